@@ -0,0 +1,159 @@
+/*
+Copyright © 2025 John, Sing Dao, Siu <john.sd.siu@gmail.com>
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+
+package cmd
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/J-Siu/go-dotfile/global"
+	"github.com/J-Siu/go-dotfile/lib"
+	"github.com/J-Siu/go-helper/v2/errs"
+	"github.com/J-Siu/go-helper/v2/file"
+	"github.com/spf13/cobra"
+)
+
+// adoptCmd represents the adopt command
+var adoptCmd = &cobra.Command{
+	Use:     "adopt [path...]",
+	Aliases: []string{"a"},
+	Short:   "Copy dotfiles from destination back to source (DirCP)",
+	Args: func(cmd *cobra.Command, args []string) error {
+		if len(args) == 0 && !global.FlagAdopt.AllModified {
+			return errors.New("requires path or --all-modified")
+		}
+		return nil
+	},
+	Run: func(cmd *cobra.Command, args []string) {
+		if err := lib.OutputCheck(global.FlagAdopt.Output); err != nil {
+			errs.Queue("adopt", err)
+			return
+		}
+		var (
+			manifest lib.TypeManifest
+			pairs    [][2]string // destination, source
+			records  lib.TypeDotfileRecords
+		)
+		if manifest.New(global.Conf.DirState).Read(); manifest.Err != nil {
+			errs.Queue("adopt: manifest", manifest.Err)
+			return
+		}
+		if global.FlagAdopt.AllModified {
+			statuses, err := lib.Status(dataRecords(), &manifest)
+			errs.Queue("adopt", err)
+			for _, s := range statuses {
+				if s.Status == lib.STATUS_DES_MODIFIED && s.SrcMode == lib.COPY {
+					pairs = append(pairs, [2]string{s.DesPath, s.SrcPath})
+				}
+			}
+		}
+		for _, arg := range args {
+			srcPath, desPath, err := adoptSrcPath(arg, &manifest)
+			if err == nil {
+				pairs = append(pairs, [2]string{desPath, srcPath})
+			}
+			errs.Queue("adopt", err)
+		}
+		for _, p := range pairs {
+			record, err := lib.Adopt(p[0], p[1], global.FlagAdopt.Save)
+			if err == nil {
+				records = append(records, record)
+				if global.FlagAdopt.Save {
+					// source and destination are the same now
+					manifest.Update(lib.TypeDotfileRecords{{
+						DesPath:      p[0],
+						FileProcMode: lib.SKIP,
+						SrcMode:      lib.COPY,
+						SrcPath:      p[1],
+					}})
+				}
+			}
+			errs.Queue("adopt", err)
+		}
+		if global.FlagAdopt.Save && len(records) > 0 {
+			errs.Queue("adopt: manifest", manifest.Write().Err)
+		}
+		// Output
+		errs.Queue("adopt", records.Output(global.FlagAdopt.Output, global.FlagAdopt.NoInfo, false, global.Flag.Verbose, global.FlagAdopt.Save))
+	},
+}
+
+func init() {
+	cmd := adoptCmd
+	rootCmd.AddCommand(cmd)
+	cmd.Flags().BoolVar(&global.FlagAdopt.AllModified, "all-modified", false, "Adopt all destination-modified files")
+	cmd.Flags().BoolVarP(&global.FlagAdopt.NoInfo, "noinfo", "n", false, "Do not print file info")
+	cmd.Flags().StringVarP(&global.FlagAdopt.Output, "output", "o", lib.OUTPUT_TABLE, "Output format: table, json, ndjson, csv")
+	cmd.Flags().BoolVarP(&global.FlagAdopt.Save, "save", "s", false, "Save changes")
+}
+
+// Source path of destination [arg], ask user if more than one candidate
+func adoptSrcPath(arg string, manifest *lib.TypeManifest) (srcPath, desPath string, err error) {
+	var (
+		exist    bool
+		i        int
+		srcPaths []string
+	)
+	if desPath, err = filepath.Abs(file.TildeEnvExpand(arg)); err == nil {
+		srcPaths, exist, err = lib.AdoptCandidates(desPath, global.Conf.DirDest, global.Conf.DirCP, manifest)
+	}
+	if err == nil && len(srcPaths) == 0 {
+		err = errors.New("no DirCP configured")
+	}
+	if err == nil && len(srcPaths) > 1 {
+		question := "Source of " + desPath + ":"
+		if !exist {
+			question = "New source of " + desPath + ":"
+		}
+		i, err = choose(question, srcPaths)
+	}
+	if err == nil {
+		srcPath = srcPaths[i]
+	}
+	return srcPath, desPath, err
+}
+
+var stdin = bufio.NewReader(os.Stdin)
+
+// Ask user to choose one of [choices], return its index
+func choose(question string, choices []string) (i int, err error) {
+	var line string
+	fmt.Println(question)
+	for i, c := range choices {
+		fmt.Printf("  %d) %s\n", i+1, c)
+	}
+	for {
+		fmt.Print("Choice [1-" + strconv.Itoa(len(choices)) + "]: ")
+		if line, err = stdin.ReadString('\n'); err != nil {
+			return 0, errors.New("no choice made: " + question)
+		}
+		if i, err = strconv.Atoi(strings.TrimSpace(line)); err == nil && i >= 1 && i <= len(choices) {
+			return i - 1, nil
+		}
+	}
+}
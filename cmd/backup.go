@@ -0,0 +1,107 @@
+/*
+Copyright © 2025 John, Sing Dao, Siu <john.sd.siu@gmail.com>
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"github.com/J-Siu/go-dotfile/global"
+	"github.com/J-Siu/go-dotfile/lib"
+	"github.com/J-Siu/go-helper/v2/errs"
+	"github.com/J-Siu/go-helper/v2/file"
+	"github.com/spf13/cobra"
+)
+
+// backupCmd represents the backup command
+var backupCmd = &cobra.Command{
+	Use:     "backup",
+	Aliases: []string{"b"},
+	Short:   "Manage backups of overwritten dotfiles",
+}
+
+// backupListCmd represents the backup list command
+var backupListCmd = &cobra.Command{
+	Use:     "list",
+	Aliases: []string{"l", "ls"},
+	Short:   "List backup run IDs",
+	Run: func(cmd *cobra.Command, args []string) {
+		var (
+			backup     lib.TypeBackup
+			tab_Writer = tabwriter.NewWriter(os.Stdout, 1, 1, 1, ' ', 0)
+		)
+		runIDs, err := lib.BackupRunIDs(global.Conf.DirData)
+		errs.Queue("backup list", err)
+		for _, runID := range runIDs {
+			files, err := backup.New(global.Conf.DirData, runID).Files()
+			errs.Queue("backup list", err)
+			state := ""
+			if backup.Read(); backup.RolledBack != nil {
+				state = "\trolled back " + backup.RolledBack.Format(time.DateTime)
+			}
+			fmt.Fprintf(tab_Writer, "%s\t%d files%s\n", runID, len(files), state)
+			if global.Flag.Verbose {
+				for _, f := range files {
+					fmt.Fprintf(tab_Writer, "\t%s\n", f)
+				}
+			}
+		}
+		tab_Writer.Flush()
+	},
+}
+
+// backupRestoreCmd represents the backup restore command
+var backupRestoreCmd = &cobra.Command{
+	Use:     "restore <run-id>",
+	Aliases: []string{"r"},
+	Short:   "Restore files of a backup run ID",
+	Args:    cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		if err := lib.OutputCheck(global.FlagBackup.Output); err != nil {
+			errs.Queue("backup restore", err)
+			return
+		}
+		var backup lib.TypeBackup
+		if !file.IsDir(backup.New(global.Conf.DirData, args[0]).Dir) {
+			errs.Queue("backup restore", fmt.Errorf("run ID not found: %s", args[0]))
+			return
+		}
+		records, err := backup.Restore(global.FlagBackup.Save)
+		errs.Queue("backup restore", err)
+		// Output
+		errs.Queue("backup restore", records.Output(global.FlagBackup.Output, global.FlagBackup.NoInfo, false, global.Flag.Verbose, global.FlagBackup.Save))
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(backupCmd)
+	backupCmd.AddCommand(backupListCmd)
+
+	cmd := backupRestoreCmd
+	backupCmd.AddCommand(cmd)
+	cmd.Flags().BoolVarP(&global.FlagBackup.NoInfo, "noinfo", "n", false, "Do not print file info")
+	cmd.Flags().StringVarP(&global.FlagBackup.Output, "output", "o", lib.OUTPUT_TABLE, "Output format: table, json, ndjson, csv")
+	cmd.Flags().BoolVarP(&global.FlagBackup.Save, "save", "s", false, "Save changes")
+}
@@ -35,9 +35,23 @@ var configCmd = &cobra.Command{
 	Short:   "Print configurations",
 	Run: func(cmd *cobra.Command, args []string) {
 		ezlog.Log().N("Config").Lm(&global.Conf).Out()
+		ezlog.Log().N("Origin").Lm(global.Conf.Origin).Out()
+	},
+}
+
+// configValidateCmd represents the config validate command
+//
+// Validation runs in TypeConf.New() for all commands, which exits on problems.
+var configValidateCmd = &cobra.Command{
+	Use:     "validate",
+	Aliases: []string{"v"},
+	Short:   "Validate configurations",
+	Run: func(cmd *cobra.Command, args []string) {
+		ezlog.Log().N(global.Conf.FileConf).M("OK").Out()
 	},
 }
 
 func init() {
 	rootCmd.AddCommand(configCmd)
+	configCmd.AddCommand(configValidateCmd)
 }
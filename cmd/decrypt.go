@@ -0,0 +1,61 @@
+/*
+Copyright © 2025 John, Sing Dao, Siu <john.sd.siu@gmail.com>
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+
+package cmd
+
+import (
+	"os"
+
+	"github.com/J-Siu/go-dotfile/global"
+	"github.com/J-Siu/go-dotfile/lib"
+	"github.com/J-Siu/go-helper/v2/errs"
+	"github.com/spf13/cobra"
+)
+
+// decryptCmd represents the decrypt command
+var decryptCmd = &cobra.Command{
+	Use:     "decrypt <file>",
+	Aliases: []string{"dec"},
+	Short:   "Decrypt an age file, to stdout by default",
+	Args:    cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		ageID := new(lib.TypeAge).New(global.Conf.Identity, global.Conf.Recipients)
+		data, err := ageID.DecryptFile(args[0])
+		if err == nil {
+			if global.FlagDecrypt.Output == "" {
+				_, err = os.Stdout.Write(data)
+			} else {
+				// plaintext secret, owner only
+				if err = os.WriteFile(global.FlagDecrypt.Output, data, 0600); err == nil {
+					err = os.Chmod(global.FlagDecrypt.Output, 0600)
+				}
+			}
+		}
+		errs.Queue("decrypt "+args[0], err)
+	},
+}
+
+func init() {
+	cmd := decryptCmd
+	rootCmd.AddCommand(cmd)
+	cmd.Flags().StringVarP(&global.FlagDecrypt.Output, "output", "o", "", "Output file, permission 0600 (default: stdout)")
+}
@@ -0,0 +1,124 @@
+/*
+Copyright © 2025 John, Sing Dao, Siu <john.sd.siu@gmail.com>
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/J-Siu/go-dotfile/global"
+	"github.com/J-Siu/go-dotfile/lib"
+	"github.com/J-Siu/go-helper/v2/errs"
+	"github.com/J-Siu/go-helper/v2/strcolor"
+	"github.com/spf13/cobra"
+)
+
+// diffCmd represents the diff command
+var diffCmd = &cobra.Command{
+	Use:     "diff",
+	Aliases: []string{"df"},
+	Short:   "Show pending changes of update",
+	Run: func(cmd *cobra.Command, args []string) {
+		var (
+			add, del   int
+			color      = isTerminal(os.Stdout) && os.Getenv("NO_COLOR") == ""
+			count      int
+			tab_Writer = tabwriter.NewWriter(os.Stdout, 1, 1, 1, ' ', 0)
+		)
+		for _, r := range dataRecords() {
+			diff, err := r.Diff()
+			errs.Queue("diff", err)
+			if diff == "" {
+				continue
+			}
+			count++
+			if global.FlagDiff.Stat {
+				a, d := lib.DiffStat(diff)
+				add += a
+				del += d
+				fmt.Fprintf(tab_Writer, " %s\t| %s\t%s\n", r.DesPath, r.FileProcMode, diffStatBar(a, d, color))
+			} else {
+				fmt.Print(diffColor(diff, color))
+			}
+		}
+		if global.FlagDiff.Stat {
+			tab_Writer.Flush()
+			fmt.Printf(" %d files changed, %d insertions(+), %d deletions(-)\n", count, add, del)
+		}
+	},
+}
+
+func init() {
+	cmd := diffCmd
+	rootCmd.AddCommand(cmd)
+	cmd.Flags().BoolVar(&global.FlagDiff.Stat, "stat", false, "Show summary only")
+}
+
+// Color lines of unified [diff]
+func diffColor(diff string, color bool) string {
+	if !color {
+		return diff
+	}
+	var (
+		hunk  bool
+		lines = strings.SplitAfter(diff, "\n")
+	)
+	for i, line := range lines {
+		content := strings.TrimSuffix(line, "\n")
+		switch {
+		case content == "":
+			continue
+		case strings.HasPrefix(line, "diff "):
+			hunk = false
+			content = strcolor.White(content)
+		case strings.HasPrefix(line, "@@"):
+			hunk = true
+			content = strcolor.Cyan(content)
+		case !hunk:
+			content = strcolor.White(content)
+		case strings.HasPrefix(line, "+"):
+			content = strcolor.Green(content)
+		case strings.HasPrefix(line, "-"):
+			content = strcolor.Red(content)
+		}
+		lines[i] = content + line[len(strings.TrimSuffix(line, "\n")):]
+	}
+	return strings.Join(lines, "")
+}
+
+// "N +++--" of [add] and [del] lines
+func diffStatBar(add, del int, color bool) string {
+	plus, minus := strings.Repeat("+", min(add, 50)), strings.Repeat("-", min(del, 50))
+	if color {
+		plus, minus = strcolor.Green(plus), strcolor.Red(minus)
+	}
+	return fmt.Sprintf("%d %s%s", add+del, plus, minus)
+}
+
+// [f] is a terminal
+func isTerminal(f *os.File) bool {
+	info, err := f.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
@@ -0,0 +1,65 @@
+/*
+Copyright © 2025 John, Sing Dao, Siu <john.sd.siu@gmail.com>
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+
+package cmd
+
+import (
+	"errors"
+
+	"github.com/J-Siu/go-dotfile/global"
+	"github.com/J-Siu/go-dotfile/lib"
+	"github.com/J-Siu/go-helper/v2/errs"
+	"github.com/J-Siu/go-helper/v2/ezlog"
+	"github.com/spf13/cobra"
+)
+
+// encryptCmd represents the encrypt command
+var encryptCmd = &cobra.Command{
+	Use:     "encrypt <file>...",
+	Aliases: []string{"enc"},
+	Short:   "Encrypt files with age, into <file>" + lib.AGE_EXT,
+	Args:    cobra.MinimumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		if global.FlagEncrypt.Output != "" && len(args) > 1 {
+			errs.Queue("encrypt", errors.New("--output with more than one file"))
+			return
+		}
+		ageID := new(lib.TypeAge).New(global.Conf.Identity, global.Conf.Recipients)
+		for _, filePath := range args {
+			outPath := global.FlagEncrypt.Output
+			if outPath == "" {
+				outPath = filePath + lib.AGE_EXT
+			}
+			if err := ageID.EncryptFile(filePath, outPath); err == nil {
+				ezlog.Log().M(filePath).M("->").M(outPath).Out()
+			} else {
+				errs.Queue("encrypt "+filePath, err)
+			}
+		}
+	},
+}
+
+func init() {
+	cmd := encryptCmd
+	rootCmd.AddCommand(cmd)
+	cmd.Flags().StringVarP(&global.FlagEncrypt.Output, "output", "o", "", "Output file (default: <file>"+lib.AGE_EXT+")")
+}
@@ -0,0 +1,80 @@
+/*
+Copyright © 2025 John, Sing Dao, Siu <john.sd.siu@gmail.com>
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+
+package cmd
+
+import (
+	"github.com/J-Siu/go-dotfile/global"
+	"github.com/J-Siu/go-dotfile/lib"
+	"github.com/J-Siu/go-helper/v2/errs"
+	"github.com/J-Siu/go-helper/v2/ezlog"
+	"github.com/spf13/cobra"
+)
+
+// pruneCmd represents the prune command
+var pruneCmd = &cobra.Command{
+	Use:     "prune",
+	Aliases: []string{"p"},
+	Short:   "Remove deployed files no longer in source",
+	Run: func(cmd *cobra.Command, args []string) {
+		if err := lib.OutputCheck(global.FlagPrune.Output); err != nil {
+			errs.Queue("prune", err)
+			return
+		}
+		var manifest lib.TypeManifest
+		if manifest.New(global.Conf.DirState).Read(); manifest.Err != nil {
+			errs.Queue("prune: manifest", manifest.Err)
+			return
+		}
+		current := updateRecords(false, nil)
+		// A source directory with error will look like all its files are gone
+		if errs.NotEmpty() {
+			ezlog.Err().N("prune").M("source directory error, nothing removed").Out()
+			return
+		}
+		var backup *lib.TypeBackup
+		if global.FlagPrune.Save {
+			backup = new(lib.TypeBackup).New(global.Conf.DirData, lib.NewRunID())
+		}
+		records, err := global.Conf.Prune(&manifest, current, global.FlagPrune.Save, backup)
+		errsQueue("prune", err)
+		// SKIP of missing destination also drops its entry
+		if global.FlagPrune.Save && len(records) > 0 {
+			// backup manifest, so rollback also restores it
+			errs.Queue("prune: manifest", backup.Save(manifest.FilePath))
+			errs.Queue("prune: manifest", manifest.Write().Err)
+		}
+		if global.FlagPrune.Save {
+			errs.Queue("prune: backup", backup.Close())
+		}
+		// Output
+		errs.Queue("prune", records.Output(global.FlagPrune.Output, global.FlagPrune.NoInfo, false, global.Flag.Verbose, global.FlagPrune.Save))
+	},
+}
+
+func init() {
+	cmd := pruneCmd
+	rootCmd.AddCommand(cmd)
+	cmd.Flags().BoolVarP(&global.FlagPrune.NoInfo, "noinfo", "n", false, "Do not print file info")
+	cmd.Flags().StringVarP(&global.FlagPrune.Output, "output", "o", lib.OUTPUT_TABLE, "Output format: table, json, ndjson, csv")
+	cmd.Flags().BoolVarP(&global.FlagPrune.Save, "save", "s", false, "Save changes")
+}
@@ -0,0 +1,99 @@
+/*
+Copyright © 2025 John, Sing Dao, Siu <john.sd.siu@gmail.com>
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/J-Siu/go-dotfile/global"
+	"github.com/J-Siu/go-dotfile/lib"
+	"github.com/J-Siu/go-helper/v2/errs"
+	"github.com/spf13/cobra"
+)
+
+// rollbackCmd represents the rollback command
+var rollbackCmd = &cobra.Command{
+	Use:     "rollback [run-id]",
+	Aliases: []string{"rb"},
+	Short:   "Undo a saved update, default the last one with change not rolled back",
+	Args:    cobra.MaximumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		if err := lib.OutputCheck(global.FlagRollback.Output); err != nil {
+			errs.Queue("rollback", err)
+			return
+		}
+		var (
+			backup lib.TypeBackup
+			runID  string
+		)
+		if len(args) == 1 {
+			runID = args[0]
+		} else {
+			var err error
+			runID, err = lib.BackupLastRunID(global.Conf.DirData, global.Conf.DirState)
+			if err == nil && runID == "" {
+				err = errors.New("no run found")
+			}
+			if err != nil {
+				errs.Queue("rollback", err)
+				return
+			}
+		}
+		if backup.New(global.Conf.DirData, runID).Read(); backup.Err != nil {
+			errs.Queue("rollback", backup.Err)
+			return
+		}
+		if !global.FlagRollback.Force {
+			if backup.RolledBack != nil {
+				errs.Queue("rollback", errors.New(runID+" already rolled back at "+backup.RolledBack.Format(time.DateTime)+", --force to roll back again"))
+				return
+			}
+			overlaps, err := backup.Overlaps()
+			for _, id := range slices.Sorted(maps.Keys(overlaps)) {
+				err = errors.Join(err, fmt.Errorf("newer run %s changed same paths: %s", id, strings.Join(overlaps[id], ", ")))
+			}
+			if err != nil {
+				errsQueue("rollback "+runID, errors.Join(err, errors.New("roll back newer runs first, or --force")))
+				return
+			}
+		}
+		records, err := backup.Rollback(global.FlagRollback.Save)
+		errsQueue("rollback "+runID, err)
+		// Output
+		errs.Queue("rollback", records.Output(global.FlagRollback.Output, global.FlagRollback.NoInfo, false, global.Flag.Verbose, global.FlagRollback.Save))
+	},
+}
+
+func init() {
+	cmd := rollbackCmd
+	rootCmd.AddCommand(cmd)
+	cmd.Flags().BoolVarP(&global.FlagRollback.Force, "force", "f", false, "Roll back even if already rolled back, or newer runs changed same paths")
+	cmd.Flags().BoolVarP(&global.FlagRollback.NoInfo, "noinfo", "n", false, "Do not print file info")
+	cmd.Flags().StringVarP(&global.FlagRollback.Output, "output", "o", lib.OUTPUT_TABLE, "Output format: table, json, ndjson, csv")
+	cmd.Flags().BoolVarP(&global.FlagRollback.Save, "save", "s", false, "Save changes")
+}
@@ -23,6 +23,7 @@ THE SOFTWARE.
 package cmd
 
 import (
+	"errors"
 	"os"
 	"runtime"
 
@@ -33,6 +34,19 @@ import (
 	"github.com/spf13/cobra"
 )
 
+// Exit code
+const (
+	EXIT_OK    = 0
+	EXIT_ERR   = 1
+	EXIT_DRIFT = 2 // status: destination not in-sync
+
+	EXIT_PENDING = EXIT_DRIFT // update: changes pending in dry run
+	EXIT_APPLIED = 3          // update: changes saved
+)
+
+// Exit code set by command, returned in Execute(). Queued errors override it with EXIT_ERR
+var exitCode = EXIT_OK
+
 var rootCmd = &cobra.Command{
 	Use:     "go-dotfile",
 	Short:   "A dotfile manager",
@@ -46,20 +60,45 @@ var rootCmd = &cobra.Command{
 			ezlog.SetLogLevel(ezlog.TRACE)
 		}
 		ezlog.Debug().N("Version").M(global.Version).Ln("Flag").Lm(&global.Flag).Out()
-		global.Conf.New()
+		if err := global.Conf.New().Err; err != nil {
+			var problems lib.TypeConfProblems
+			if errors.As(err, &problems) {
+				for _, p := range problems {
+					ezlog.Err().M(p).Out()
+				}
+			} else {
+				ezlog.Err().M(err).Out()
+			}
+			os.Exit(EXIT_ERR)
+		}
 	},
 	PersistentPostRun: func(cmd *cobra.Command, args []string) {
 		if errs.NotEmpty() {
 			ezlog.Err().M(errs.Errs()).Out()
+			exitCode = EXIT_ERR
 		}
 	},
 }
 
+// Queue [err] with errs.Queue, each of joined errors separately
+func errsQueue(prefix string, err error) {
+	if joined, ok := err.(interface{ Unwrap() []error }); ok {
+		if _, ok := err.(*lib.TypeError); !ok {
+			for _, e := range joined.Unwrap() {
+				errsQueue(prefix, e)
+			}
+			return
+		}
+	}
+	errs.Queue(prefix, err)
+}
+
 func Execute() {
 	err := rootCmd.Execute()
 	if err != nil {
-		os.Exit(1)
+		os.Exit(EXIT_ERR)
 	}
+	os.Exit(exitCode)
 }
 
 func init() {
@@ -73,7 +112,9 @@ func init() {
 
 	cmd := rootCmd
 	cmd.PersistentFlags().BoolVarP(&global.Flag.Debug, "debug", "d", false, "Enable debug")
+	cmd.PersistentFlags().IntVarP(&global.Flag.Jobs, "jobs", "j", 0, "Files processed in parallel (default: number of CPU)")
 	cmd.PersistentFlags().BoolVarP(&global.Flag.Trace, "trace", "t", false, "Enable trace")
 	cmd.PersistentFlags().BoolVarP(&global.Flag.Verbose, "verbose", "v", false, "Verbose")
-	cmd.PersistentFlags().StringVarP(&global.Conf.FileConf, "config", "c", lib.Default.FileConf, "Config file")
+	cmd.PersistentFlags().StringVarP(&global.Conf.FileConf, "config", "c", "", "Config file, json/yaml/toml (default: $XDG_CONFIG_HOME/go-dotfile/config.{json,yaml,yml,toml} or "+lib.Default.FileConf+")")
+	cmd.PersistentFlags().StringVarP(&global.Conf.Profile, "profile", "p", "", "Profiles, comma separated (default: "+lib.ENV_PROFILE+" or by hostname)")
 }
@@ -0,0 +1,59 @@
+/*
+Copyright © 2025 John, Sing Dao, Siu <john.sd.siu@gmail.com>
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+
+package cmd
+
+import (
+	"github.com/J-Siu/go-dotfile/global"
+	"github.com/J-Siu/go-dotfile/lib"
+	"github.com/J-Siu/go-helper/v2/errs"
+	"github.com/spf13/cobra"
+)
+
+// statusCmd represents the status command
+var statusCmd = &cobra.Command{
+	Use:     "status",
+	Aliases: []string{"s", "st"},
+	Short:   "Show drift between source and deployed dotfiles",
+	Long:    "Show drift between source and deployed dotfiles. Exit code is 2 if any file is not in-sync.",
+	Run: func(cmd *cobra.Command, args []string) {
+		var manifest lib.TypeManifest
+		if manifest.New(global.Conf.DirState).Read(); manifest.Err != nil {
+			errs.Queue("status: manifest", manifest.Err)
+			return
+		}
+		statuses, err := lib.Status(dataRecords(), &manifest)
+		if err == nil {
+			err = statuses.Output(global.FlagStatus.Output, global.Flag.Verbose)
+		}
+		errs.Queue("status", err)
+		if statuses.Drift() {
+			exitCode = EXIT_DRIFT
+		}
+	},
+}
+
+func init() {
+	cmd := statusCmd
+	rootCmd.AddCommand(cmd)
+	cmd.Flags().StringVarP(&global.FlagStatus.Output, "output", "o", "table", "Output format: table, json")
+}
@@ -23,53 +23,133 @@ THE SOFTWARE.
 package cmd
 
 import (
+	"time"
+
 	"github.com/J-Siu/go-dotfile/global"
 	"github.com/J-Siu/go-dotfile/lib"
+	"github.com/J-Siu/go-helper/v2/errs"
+	"github.com/J-Siu/go-helper/v2/ezlog"
 	"github.com/spf13/cobra"
 )
 
-type ModeDirPair struct {
-	Mode lib.FileProcMode
-	Dirs *[]string
-}
-
 // updateCmd represents the update command
 var updateCmd = &cobra.Command{
 	Use:     "update",
 	Aliases: []string{"u", "up"},
 	Short:   "Update dotfiles",
 	Run: func(cmd *cobra.Command, args []string) {
+		if err := lib.OutputCheck(global.FlagUpdate.Output); err != nil {
+			errs.Queue("update", err)
+			return
+		}
 		var (
-			df            lib.TypeDotfile
-			mode_dir_pair = []ModeDirPair{
-				{lib.COPY, &global.Conf.DirCP},
-				{lib.APPEND, &global.Conf.DirAP},
-			}
-			property = lib.TypeDotfileProperty{
-				DirDest:  &global.Conf.DirDest,
-				DirSkip:  &global.Conf.DirSkip,
-				Save:     global.FlagUpdate.Save,
-				FileSkip: &global.Conf.FileSkip,
-			}
-			records lib.TypeDotfileRecords
+			backup *lib.TypeBackup
+			start  = time.Now()
 		)
-		for _, m := range mode_dir_pair {
-			property.Mode = m.Mode
-			for _, dir := range *m.Dirs {
-				property.DirSrc = &dir
-				df.New(&property).Run()
-				records = append(records, df.Records...)
+		if global.FlagUpdate.Save {
+			backup = new(lib.TypeBackup).New(global.Conf.DirData, lib.NewRunID())
+			// Pre-update hooks, with changes of a dry run. Nothing is changed if dry run or hook fails.
+			if len(global.Conf.Hooks.PreUpdate) > 0 {
+				pending := updateRecords(false, nil)
+				if errs.NotEmpty() {
+					return
+				}
+				if err := global.Conf.Hooks.Run(lib.HOOK_PRE_UPDATE, pending, global.Conf.DirDest, backup.RunID); err != nil {
+					errs.Queue("update: hook", err)
+					return
+				}
+			}
+		}
+		records := updateRecords(global.FlagUpdate.Save, backup)
+		scripts := updateScripts(global.FlagUpdate.Save)
+		// Manifest
+		if global.FlagUpdate.Save {
+			var manifest lib.TypeManifest
+			manifest.New(global.Conf.DirState).Read().Update(records)
+			// backup manifest, so rollback also restores it. No new run if nothing changed.
+			if manifest.Err == nil && records.Changed() {
+				manifest.Err = backup.Save(manifest.FilePath)
 			}
+			errs.Queue("update: manifest", manifest.Write().Err)
+			errs.Queue("update: backup", backup.Close())
+			// Hooks
+			errs.Queue("update: hook", global.Conf.Hooks.Run(lib.HOOK_PATH, records, global.Conf.DirDest, backup.RunID))
+			errs.Queue("update: hook", global.Conf.Hooks.Run(lib.HOOK_POST_UPDATE, records, global.Conf.DirDest, backup.RunID))
 		}
 		// Output
-		records.Output(global.FlagUpdate.NoInfo, global.FlagUpdate.Quiet, global.Flag.Verbose, global.FlagUpdate.Save)
+		errs.Queue("update", records.Output(global.FlagUpdate.Output, global.FlagUpdate.NoInfo, global.FlagUpdate.Quiet, global.Flag.Verbose, global.FlagUpdate.Save))
+		// Summary
+		summary := records.Summary(global.FlagUpdate.Save)
+		summary.Duration = time.Since(start)
+		summary.Errors = errs.Len()
+		summary.Scripts = len(scripts)
+		if global.FlagUpdate.Output == lib.OUTPUT_TABLE {
+			for _, script := range scripts {
+				if global.FlagUpdate.Save {
+					ezlog.Log().M("RUN").M(script.Path).Out()
+				} else {
+					ezlog.Log().M("DryRun: RUN").M(script.Path).Out()
+				}
+			}
+			ezlog.Log().N("Summary").M(summary.String()).Out()
+		}
+		switch {
+		case !summary.Changed():
+		case global.FlagUpdate.Save:
+			exitCode = EXIT_APPLIED
+		default:
+			exitCode = EXIT_PENDING
+		}
 	},
 }
 
+// Process all source directories in configuration, errors are queued
+//   - [save] = true: save, false: dry run
+//   - [backup] = backup destination before overwrite, nil: no backup
+func updateRecords(save bool, backup *lib.TypeBackup) lib.TypeDotfileRecords {
+	records, err := global.Conf.Process(save, global.Flag.Jobs, backup)
+	errsQueue("update", err)
+	return records
+}
+
+// Run pending scripts of source directories, stop at first failure, errors are queued
+//   - [save] = true: run, false: dry run
+//
+// Return scripts run, or to be run in dry run
+func updateScripts(save bool) (scripts []*lib.TypeScript) {
+	var state lib.TypeScripts
+	if state.New(global.Conf.DirState).Read(); state.Err != nil {
+		errs.Queue("update: scripts", state.Err)
+		return nil
+	}
+	pending, err := state.Pending(global.Conf.SrcDirs(), global.Conf.DirScripts)
+	if err != nil || !save {
+		errs.Queue("update: scripts", err)
+		return pending
+	}
+	for _, script := range pending {
+		if state.Run(script, global.Conf.DirDest); state.Err != nil {
+			errs.Queue("update: script "+script.Path, state.Err)
+			break
+		}
+		scripts = append(scripts, script)
+	}
+	return scripts
+}
+
+// Process all source directories in dry run, with content after processing in records,
+// eg. for status and diff. Errors are queued.
+func dataRecords() lib.TypeDotfileRecords {
+	records, err := global.Conf.ProcessData(global.Flag.Jobs)
+	errsQueue("update", err)
+	return records
+}
+
 func init() {
 	cmd := updateCmd
 	rootCmd.AddCommand(cmd)
 	cmd.Flags().BoolVarP(&global.FlagUpdate.NoInfo, "noinfo", "n", false, "Do not print file info")
+	cmd.Flags().StringVarP(&global.FlagUpdate.Output, "output", "o", lib.OUTPUT_TABLE, "Output format: table, json, ndjson, csv")
 	cmd.Flags().BoolVarP(&global.FlagUpdate.Quiet, "quiet", "q", false, "Show non-skip file only")
 	cmd.Flags().BoolVarP(&global.FlagUpdate.Save, "save", "s", false, "Save changes")
 }
@@ -25,7 +25,15 @@ package global
 import "github.com/J-Siu/go-dotfile/lib"
 
 var (
-	Conf       lib.TypeConf
-	Flag       lib.TypeFlag
-	FlagUpdate lib.TypeFlagUpdate
+	Conf         lib.TypeConf
+	Flag         lib.TypeFlag
+	FlagAdopt    lib.TypeFlagAdopt
+	FlagBackup   lib.TypeFlagBackup
+	FlagDecrypt  lib.TypeFlagDecrypt
+	FlagDiff     lib.TypeFlagDiff
+	FlagEncrypt  lib.TypeFlagEncrypt
+	FlagPrune    lib.TypeFlagPrune
+	FlagRollback lib.TypeFlagRollback
+	FlagStatus   lib.TypeFlagStatus
+	FlagUpdate   lib.TypeFlagUpdate
 )
@@ -0,0 +1,126 @@
+/*
+Copyright © 2025 John, Sing Dao, Siu <john.sd.siu@gmail.com>
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+
+package lib
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/J-Siu/go-helper/v2/file"
+)
+
+// Source paths that can own [desPath], reverse of hiddenPath
+//   - [dirDest] = destination directory
+//   - [dirSrcs] = source directories, eg. DirCP
+//
+// If [manifest] has [desPath] deployed from [dirSrcs], its source paths are returned.
+// Else existing source files, eg. "bashrc", ".bashrc" or "bashrc.tmpl" for ".bashrc", are returned.
+// If none exist, a new path without leading dot in each of [dirSrcs] is returned, with [exist] false.
+func AdoptCandidates(desPath, dirDest string, dirSrcs []string, manifest *TypeManifest) (srcPaths []string, exist bool, err error) {
+	var rel string
+	if rel, err = filepath.Rel(dirDest, desPath); err != nil {
+		return nil, false, err
+	}
+	if rel == "." || strings.HasPrefix(rel, "../") || !strings.HasPrefix(rel, ".") {
+		return nil, false, errors.New("not a dotfile in " + dirDest + ": " + desPath)
+	}
+	// Manifest
+	if manifest != nil {
+		for _, e := range manifest.Get(desPath) {
+			for _, dir := range dirSrcs {
+				if e.SrcMode == COPY && strings.HasPrefix(e.SrcPath, filepath.Clean(dir)+"/") {
+					srcPaths = append(srcPaths, e.SrcPath)
+				}
+			}
+		}
+		if len(srcPaths) > 0 {
+			return srcPaths, true, nil
+		}
+	}
+	// Existing source files
+	rels := []string{rel, rel[1:], rel + TMPL_EXT, rel[1:] + TMPL_EXT}
+	for _, dir := range dirSrcs {
+		for _, r := range rels {
+			p := filepath.Join(dir, r)
+			if _, e := os.Stat(p); e == nil {
+				srcPaths = append(srcPaths, p)
+			}
+		}
+	}
+	if len(srcPaths) > 0 {
+		return srcPaths, true, nil
+	}
+	// New source files
+	for _, dir := range dirSrcs {
+		srcPaths = append(srcPaths, filepath.Join(dir, rel[1:]))
+	}
+	return srcPaths, false, nil
+}
+
+// Copy [desPath] back to [srcPath], with permission and modification time
+//   - [save] = true: save, false: dry run
+//
+// Returned record is the copy [desPath] -> [srcPath], so its SrcPath is [desPath].
+// A template or encrypted [srcPath] is an error, as the deployed file cannot replace it.
+func Adopt(desPath, srcPath string, save bool) (record *TypeDotfileRecord, err error) {
+	var (
+		desInfo os.FileInfo
+		srcInfo os.FileInfo
+	)
+	record = &TypeDotfileRecord{
+		DesPath:      srcPath,
+		FileProcMode: COPY,
+		Save:         save,
+		SrcMode:      COPY,
+		SrcPath:      desPath,
+	}
+	if isTemplate(srcPath) {
+		return record, errors.New("source is a template, edit it instead: " + srcPath)
+	}
+	if isEncrypted(srcPath) {
+		return record, errors.New("source is encrypted, use encrypt instead: " + srcPath)
+	}
+	if desInfo, err = os.Stat(desPath); err == nil {
+		record.SrcInfo = &desInfo
+		if !desInfo.Mode().IsRegular() {
+			err = errors.New("not a regular file: " + desPath)
+		}
+	}
+	if err == nil {
+		if srcInfo, err = os.Stat(srcPath); err == nil {
+			record.DesInfo = &srcInfo
+		}
+		err = nil // source may not exist
+	}
+	if err == nil && file.FileSame(desPath, srcPath) {
+		record.FileProcMode = SKIP
+	}
+	if err == nil && record.FileProcMode == COPY && save {
+		if err = os.MkdirAll(filepath.Dir(srcPath), os.ModePerm); err == nil {
+			err = copyFile(desPath, srcPath)
+		}
+	}
+	return record, err
+}
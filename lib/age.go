@@ -0,0 +1,156 @@
+/*
+Copyright © 2025 John, Sing Dao, Siu <john.sd.siu@gmail.com>
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+
+package lib
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"os"
+	"strings"
+	"sync"
+
+	"filippo.io/age"
+)
+
+// Extension of encrypted source file, age format
+const AGE_EXT = ".age"
+
+// Default identity file name, in $XDG_CONFIG_HOME/go-dotfile
+const FILE_IDENTITY = "identity.txt"
+
+// Permission bits removed from deployed decrypted files, group and other
+const AGE_PERM_MASK os.FileMode = 0077
+
+// age identity and recipients, identity file is read once on first use
+type TypeAge struct {
+	IdentityFile string   `json:"IdentityFile"` // age identity file, eg. from age-keygen
+	Recipients   []string `json:"Recipients"`   // extra recipients of encrypt, age public keys
+
+	identities []age.Identity
+	err        error
+	once       sync.Once
+}
+
+func (t *TypeAge) New(identityFile string, recipients []string) *TypeAge {
+	t.IdentityFile = identityFile
+	t.Recipients = recipients
+	return t
+}
+
+// Identities in IdentityFile
+func (t *TypeAge) load() ([]age.Identity, error) {
+	t.once.Do(func() {
+		var f *os.File
+		if f, t.err = os.Open(t.IdentityFile); t.err == nil {
+			defer f.Close()
+			t.identities, t.err = age.ParseIdentities(f)
+		}
+		if t.err != nil {
+			t.err = errors.New("age identity: " + t.err.Error())
+		}
+	})
+	return t.identities, t.err
+}
+
+// Decrypt [data] with identities in IdentityFile
+func (t *TypeAge) Decrypt(data []byte) (out []byte, err error) {
+	var (
+		identities []age.Identity
+		r          io.Reader
+	)
+	if identities, err = t.load(); err == nil {
+		r, err = age.Decrypt(bytes.NewReader(data), identities...)
+	}
+	if err == nil {
+		out, err = io.ReadAll(r)
+	}
+	return out, err
+}
+
+// Encrypt [data] to recipients of identities in IdentityFile and Recipients
+func (t *TypeAge) Encrypt(data []byte) (out []byte, err error) {
+	var (
+		buf        bytes.Buffer
+		identities []age.Identity
+		recipients []age.Recipient
+		w          io.WriteCloser
+	)
+	if identities, err = t.load(); err != nil {
+		return nil, err
+	}
+	for _, i := range identities {
+		if x, ok := i.(*age.X25519Identity); ok {
+			recipients = append(recipients, x.Recipient())
+		}
+	}
+	for _, s := range t.Recipients {
+		var r *age.X25519Recipient
+		if r, err = age.ParseX25519Recipient(s); err != nil {
+			return nil, err
+		}
+		recipients = append(recipients, r)
+	}
+	if w, err = age.Encrypt(&buf, recipients...); err == nil {
+		if _, err = w.Write(data); err == nil {
+			err = w.Close()
+		}
+	}
+	return buf.Bytes(), err
+}
+
+// Encrypt [filePath] to [outPath], with same permission
+func (t *TypeAge) EncryptFile(filePath, outPath string) (err error) {
+	var (
+		data []byte
+		info os.FileInfo
+	)
+	if info, err = os.Stat(filePath); err == nil {
+		data, err = os.ReadFile(filePath)
+	}
+	if err == nil {
+		data, err = t.Encrypt(data)
+	}
+	if err == nil {
+		err = writeAtomic(outPath, data, info.Mode().Perm())
+	}
+	return err
+}
+
+// Decrypt [filePath]
+func (t *TypeAge) DecryptFile(filePath string) (data []byte, err error) {
+	if data, err = os.ReadFile(filePath); err == nil {
+		data, err = t.Decrypt(data)
+	}
+	return data, err
+}
+
+// Check if [p] is encrypted, eg. token.age, token.age##host.vm
+func isEncrypted(p string) bool {
+	return strings.HasSuffix(trimAlt(p), AGE_EXT)
+}
+
+// Strip encrypted extension from [p]
+func trimEncrypted(p string) string {
+	return strings.TrimSuffix(p, AGE_EXT)
+}
@@ -0,0 +1,109 @@
+/*
+Copyright © 2025 John, Sing Dao, Siu <john.sd.siu@gmail.com>
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+
+package lib
+
+import (
+	"os"
+	"path"
+	"runtime"
+	"strings"
+)
+
+// Separator of alternate conditions in file name, eg. gitconfig##os.linux,arch.arm64
+const ALT_SEP = "##"
+
+// Condition keys of alternate, with weight. Higher total weight is more specific.
+// A host match outweighs os and arch together.
+var AltKeys = map[string]int{
+	"arch": 1,
+	"os":   2,
+	"host": 4,
+}
+
+// Split [p] into path without alternate conditions and the conditions, eg.
+// "dir/gitconfig##os.linux" -> "dir/gitconfig", "os.linux"
+func altSplit(p string) (base, cond string) {
+	dir, name := path.Split(p)
+	if i := strings.Index(name, ALT_SEP); i > 0 {
+		return dir + name[:i], name[i+len(ALT_SEP):]
+	}
+	return p, ""
+}
+
+// Strip alternate conditions from [p]
+func trimAlt(p string) string {
+	base, _ := altSplit(p)
+	return base
+}
+
+// Score of alternate conditions [cond] against current machine
+//   - -1: not matching, or unknown key
+//   - 0: no condition
+//   - else: sum of weight in [AltKeys]
+//
+// Values are case-insensitive, eg. os.Linux, os.Darwin.
+func altScore(cond string) (score int) {
+	if cond == "" {
+		return 0
+	}
+	hostname, _ := os.Hostname()
+	machine := map[string]string{
+		"arch": runtime.GOARCH,
+		"host": hostname,
+		"os":   runtime.GOOS,
+	}
+	for _, c := range strings.Split(cond, ",") {
+		key, value, _ := strings.Cut(c, ".")
+		weight, ok := AltKeys[key]
+		if !ok || !strings.EqualFold(value, machine[key]) {
+			return -1
+		}
+		score += weight
+	}
+	return score
+}
+
+// Keep best matching alternate of each file in [files], in place of the first of its alternates.
+// Files with no matching alternate are dropped. On tie, the first one is kept.
+func altSelect(files []string) (selected []string) {
+	var (
+		best  = make(map[string]int) // base -> index in selected
+		score = make(map[string]int) // base -> score of selected
+	)
+	for _, f := range files {
+		base, cond := altSplit(f)
+		s := altScore(cond)
+		if s < 0 {
+			continue
+		}
+		if i, ok := best[base]; !ok {
+			best[base] = len(selected)
+			score[base] = s
+			selected = append(selected, f)
+		} else if s > score[base] {
+			score[base] = s
+			selected[i] = f
+		}
+	}
+	return selected
+}
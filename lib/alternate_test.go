@@ -0,0 +1,114 @@
+/*
+Copyright © 2025 John, Sing Dao, Siu <john.sd.siu@gmail.com>
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+
+package lib
+
+import (
+	"os"
+	"reflect"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+// Other value than [v], for not matching condition
+func otherThan(v string) string {
+	return v + "x"
+}
+
+func TestAltScore(t *testing.T) {
+	host, _ := os.Hostname()
+	tests := []struct {
+		cond string
+		want int
+	}{
+		{"", 0},
+		{"os." + runtime.GOOS, 2},
+		{"os." + strings.ToUpper(runtime.GOOS), 2},
+		{"arch." + runtime.GOARCH, 1},
+		{"host." + host, 4},
+		{"os." + runtime.GOOS + ",arch." + runtime.GOARCH, 3},
+		{"host." + host + ",os." + runtime.GOOS + ",arch." + runtime.GOARCH, 7},
+		{"os." + otherThan(runtime.GOOS), -1},
+		{"os." + runtime.GOOS + ",arch." + otherThan(runtime.GOARCH), -1},
+		{"distro.debian", -1},
+		{"os", -1},
+	}
+	for _, tt := range tests {
+		if got := altScore(tt.cond); got != tt.want {
+			t.Errorf("altScore(%q) = %d, want %d", tt.cond, got, tt.want)
+		}
+	}
+}
+
+func TestAltSelect(t *testing.T) {
+	host, _ := os.Hostname()
+	var (
+		osAlt    = "##os." + runtime.GOOS
+		archAlt  = "##arch." + runtime.GOARCH
+		hostAlt  = "##host." + host
+		otherAlt = "##os." + otherThan(runtime.GOOS)
+	)
+	tests := []struct {
+		name  string
+		files []string
+		want  []string
+	}{
+		{
+			name:  "no alternate",
+			files: []string{"a", "b"},
+			want:  []string{"a", "b"},
+		},
+		{
+			name:  "most specific wins, in place of first",
+			files: []string{"a", "b", "b" + archAlt, "b" + osAlt, "c", "b" + hostAlt},
+			want:  []string{"a", "b" + hostAlt, "c"},
+		},
+		{
+			name:  "fallback without condition",
+			files: []string{"a" + otherAlt, "a"},
+			want:  []string{"a"},
+		},
+		{
+			name:  "no matching alternate dropped",
+			files: []string{"a" + otherAlt, "b"},
+			want:  []string{"b"},
+		},
+		{
+			name:  "tie keeps first",
+			files: []string{"a##os." + runtime.GOOS, "a##os." + strings.ToUpper(runtime.GOOS)},
+			want:  []string{"a##os." + runtime.GOOS},
+		},
+		{
+			name:  "same name in different directories",
+			files: []string{"d1/a" + osAlt, "d2/a"},
+			want:  []string{"d1/a" + osAlt, "d2/a"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := altSelect(tt.files); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("altSelect() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
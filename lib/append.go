@@ -0,0 +1,230 @@
+/*
+Copyright © 2025 John, Sing Dao, Siu <john.sd.siu@gmail.com>
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+
+package lib
+
+import (
+	"bytes"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+const (
+	COMMENT_DEFAULT = "#"
+	MARKER_BEGIN    = ">>> go-dotfile:"
+	MARKER_END      = "<<< go-dotfile:"
+)
+
+// Comment syntax by lowercase extension of dotted destination file name, eg. ".vimrc", ".vim".
+//
+// A value with space is a comment with begin and end, eg. "<!-- -->"
+var CommentDefault = map[string]string{
+	".css":        "/* */",
+	".el":         ";;",
+	".emacs":      ";;",
+	".exrc":       `"`,
+	".gvimrc":     `"`,
+	".html":       "<!-- -->",
+	".ini":        ";",
+	".lua":        "--",
+	".sql":        "--",
+	".vim":        `"`,
+	".vimrc":      `"`,
+	".xml":        "<!-- -->",
+	".xresources": "!",
+}
+
+// Comment begin and end of [desPath]. [comment] overrides [CommentDefault].
+func commentOf(desPath string, comment *map[string]string) (open, close string) {
+	var (
+		ext = strings.ToLower(path.Ext(desPath))
+		str = COMMENT_DEFAULT
+	)
+	if s, ok := CommentDefault[ext]; ok {
+		str = s
+	}
+	if comment != nil {
+		if s, ok := (*comment)[ext]; ok {
+			str = s
+		}
+	}
+	open, close, _ = strings.Cut(str, " ")
+	return open, close
+}
+
+// Return [des] with block of [src] replaced, or added at the end if not found.
+//
+// Block is [src] wrapped in begin/end marker lines with [label]:
+//
+//	# >>> go-dotfile: [label] >>>
+//	[src]
+//	# <<< go-dotfile: [label] <<<
+func appendBlock(des, src []byte, label, open, close string) []byte {
+	var (
+		begin = markerLine(open, close, MARKER_BEGIN, label, ">>>")
+		end   = markerLine(open, close, MARKER_END, label, "<<<")
+		block []byte
+		out   []byte
+	)
+	block = append(block, begin...)
+	block = append(block, src...)
+	if len(src) > 0 && src[len(src)-1] != '\n' {
+		block = append(block, '\n')
+	}
+	block = append(block, end...)
+
+	if i := lineIndex(des, begin); i >= 0 {
+		if j := lineIndex(des[i:], end); j >= 0 {
+			out = append(out, des[:i]...)
+			out = append(out, block...)
+			return append(out, des[i+j+len(end):]...)
+		}
+	}
+	out = append(out, des...)
+	if len(out) > 0 && out[len(out)-1] != '\n' {
+		out = append(out, '\n')
+	}
+	return append(out, block...)
+}
+
+// Return [des] with block of [label] removed, and true if found
+func removeBlock(des []byte, label, open, close string) ([]byte, bool) {
+	var (
+		begin = markerLine(open, close, MARKER_BEGIN, label, ">>>")
+		end   = markerLine(open, close, MARKER_END, label, "<<<")
+	)
+	if i := lineIndex(des, begin); i >= 0 {
+		if j := lineIndex(des[i:], end); j >= 0 {
+			return append(des[:i:i], des[i+j+len(end):]...), true
+		}
+	}
+	return des, false
+}
+
+// Return [des] with blocks of alternates of [label] removed, eg. "label##os.linux".
+// Blocks of earlier versions are labelled with the chosen alternate.
+func removeAltBlocks(des []byte, label, open, close string) []byte {
+	prefix := []byte(open + " " + MARKER_BEGIN + " " + label + ALT_SEP)
+	for {
+		i := lineIndex(des, prefix)
+		if i < 0 {
+			return des
+		}
+		// rest of line after [label], eg. "##os.linux >>>"
+		line, _, _ := bytes.Cut(des[i+len(prefix)-len(ALT_SEP):], []byte("\n"))
+		alt, _, _ := bytes.Cut(line, []byte(" >>>"))
+		altLabel := label + string(alt)
+		var found bool
+		if des, found = removeBlock(des, altLabel, open, close); !found {
+			return des
+		}
+	}
+}
+
+// Return [des] with block of [srcPath] of source directory [root] replaced by [src], see [appendBlock].
+// Blocks of its alternates are removed, block labelled by earlier versions is replaced in place.
+func appendSrc(des, src []byte, root, srcPath, open, close string) []byte {
+	var (
+		label  = blockLabel(root, srcPath)
+		legacy = blockLabelV1(srcPath)
+	)
+	des = relabelBlock(removeAltBlocks(des, legacy, open, close), legacy, label, open, close)
+	return appendBlock(removeAltBlocks(des, label, open, close), src, label, open, close)
+}
+
+// Block label of [srcPath] in source directory [root], same for all its alternates.
+//
+// Last 2 elements of [root] and path relative to it, eg. "df_pub/append/.bashrc", so it
+// does not change when the repository is moved, and is different for each source directory.
+func blockLabel(root, srcPath string) string {
+	abs, err := filepath.Abs(root)
+	if err != nil {
+		return blockLabelV1(srcPath)
+	}
+	rel, err := filepath.Rel(abs, trimAlt(srcPath))
+	if err != nil {
+		return blockLabelV1(srcPath)
+	}
+	return path.Join(path.Base(path.Dir(abs)), path.Base(abs), rel)
+}
+
+// Block label of [srcPath] of earlier versions, "~" path of the source
+func blockLabelV1(srcPath string) string {
+	return tildePath(trimAlt(srcPath))
+}
+
+// Return [des] with marker lines of block [label] changed to [newLabel]
+func relabelBlock(des []byte, label, newLabel, open, close string) []byte {
+	var (
+		begin = markerLine(open, close, MARKER_BEGIN, label, ">>>")
+		end   = markerLine(open, close, MARKER_END, label, "<<<")
+		out   []byte
+	)
+	if i := lineIndex(des, begin); i >= 0 {
+		if j := lineIndex(des[i:], end); j >= 0 {
+			out = append(out, des[:i]...)
+			out = append(out, markerLine(open, close, MARKER_BEGIN, newLabel, ">>>")...)
+			out = append(out, des[i+len(begin):i+j]...)
+			out = append(out, markerLine(open, close, MARKER_END, newLabel, "<<<")...)
+			return append(out, des[i+j+len(end):]...)
+		}
+	}
+	return des
+}
+
+// Index of [line] in [data], matching whole line only. -1 if not found.
+func lineIndex(data, line []byte) int {
+	for i := 0; i < len(data); {
+		j := bytes.Index(data[i:], line)
+		if j < 0 {
+			return -1
+		}
+		if i+j == 0 || data[i+j-1] == '\n' {
+			return i + j
+		}
+		i += j + 1
+	}
+	return -1
+}
+
+func markerLine(open, close, marker, label, arrow string) []byte {
+	line := open + " " + marker + " " + label + " " + arrow
+	if close != "" {
+		line += " " + close
+	}
+	return []byte(line + "\n")
+}
+
+// Replace home directory prefix of [p] with "~"
+func tildePath(p string) string {
+	if home, e := os.UserHomeDir(); e == nil && home != "" {
+		if p == home {
+			return "~"
+		}
+		if strings.HasPrefix(p, home+"/") {
+			return "~" + p[len(home):]
+		}
+	}
+	return p
+}
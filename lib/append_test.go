@@ -0,0 +1,200 @@
+/*
+Copyright © 2025 John, Sing Dao, Siu <john.sd.siu@gmail.com>
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+
+package lib
+
+import (
+	"os"
+	"testing"
+)
+
+func TestAppendBlock(t *testing.T) {
+	tests := []struct {
+		name        string
+		des, src    string
+		open, close string
+		want        string
+	}{
+		{
+			name: "empty destination",
+			src:  "a\n", open: "#",
+			want: "# >>> go-dotfile: l >>>\na\n# <<< go-dotfile: l <<<\n",
+		},
+		{
+			name: "added at end, newline before block",
+			des:  "x", src: "a", open: "#",
+			want: "x\n# >>> go-dotfile: l >>>\na\n# <<< go-dotfile: l <<<\n",
+		},
+		{
+			name: "replaced in place",
+			des:  "x\n# >>> go-dotfile: l >>>\nold\nold\n# <<< go-dotfile: l <<<\ny\n",
+			src:  "new\n", open: "#",
+			want: "x\n# >>> go-dotfile: l >>>\nnew\n# <<< go-dotfile: l <<<\ny\n",
+		},
+		{
+			name: "comment with end",
+			des:  "<a/>\n", src: "<b/>\n", open: "<!--", close: "-->",
+			want: "<a/>\n<!-- >>> go-dotfile: l >>> -->\n<b/>\n<!-- <<< go-dotfile: l <<< -->\n",
+		},
+		{
+			name: "marker not at line start is not a block",
+			des:  "echo # >>> go-dotfile: l >>>\n", src: "a\n", open: "#",
+			want: "echo # >>> go-dotfile: l >>>\n# >>> go-dotfile: l >>>\na\n# <<< go-dotfile: l <<<\n",
+		},
+		{
+			name: "begin without end is added again",
+			des:  "# >>> go-dotfile: l >>>\n", src: "a\n", open: "#",
+			want: "# >>> go-dotfile: l >>>\n# >>> go-dotfile: l >>>\na\n# <<< go-dotfile: l <<<\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := string(appendBlock([]byte(tt.des), []byte(tt.src), "l", tt.open, tt.close)); got != tt.want {
+				t.Errorf("appendBlock() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRemoveBlock(t *testing.T) {
+	tests := []struct {
+		name      string
+		des       string
+		want      string
+		wantFound bool
+	}{
+		{
+			name:      "removed",
+			des:       "x\n# >>> go-dotfile: l >>>\na\n# <<< go-dotfile: l <<<\ny\n",
+			want:      "x\ny\n",
+			wantFound: true,
+		},
+		{
+			name: "other label kept",
+			des:  "# >>> go-dotfile: m >>>\na\n# <<< go-dotfile: m <<<\n",
+			want: "# >>> go-dotfile: m >>>\na\n# <<< go-dotfile: m <<<\n",
+		},
+		{
+			name: "label is not a prefix",
+			des:  "# >>> go-dotfile: l##os.linux >>>\na\n# <<< go-dotfile: l##os.linux <<<\n",
+			want: "# >>> go-dotfile: l##os.linux >>>\na\n# <<< go-dotfile: l##os.linux <<<\n",
+		},
+		{
+			name: "begin without end kept",
+			des:  "# >>> go-dotfile: l >>>\na\n",
+			want: "# >>> go-dotfile: l >>>\na\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, found := removeBlock([]byte(tt.des), "l", "#", "")
+			if string(got) != tt.want || found != tt.wantFound {
+				t.Errorf("removeBlock() = %q, %v, want %q, %v", got, found, tt.want, tt.wantFound)
+			}
+		})
+	}
+}
+
+func TestRemoveAltBlocks(t *testing.T) {
+	tests := []struct {
+		name string
+		des  string
+		want string
+	}{
+		{
+			name: "alternates removed",
+			des: "x\n# >>> go-dotfile: l##os.linux >>>\na\n# <<< go-dotfile: l##os.linux <<<\n" +
+				"# >>> go-dotfile: l##host.h,arch.arm64 >>>\nb\n# <<< go-dotfile: l##host.h,arch.arm64 <<<\ny\n",
+			want: "x\ny\n",
+		},
+		{
+			name: "block of label and other labels kept",
+			des: "# >>> go-dotfile: l >>>\na\n# <<< go-dotfile: l <<<\n" +
+				"# >>> go-dotfile: l2##os.linux >>>\nb\n# <<< go-dotfile: l2##os.linux <<<\n",
+			want: "# >>> go-dotfile: l >>>\na\n# <<< go-dotfile: l <<<\n" +
+				"# >>> go-dotfile: l2##os.linux >>>\nb\n# <<< go-dotfile: l2##os.linux <<<\n",
+		},
+		{
+			name: "alternate without end kept",
+			des:  "# >>> go-dotfile: l##os.linux >>>\na\n",
+			want: "# >>> go-dotfile: l##os.linux >>>\na\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := string(removeAltBlocks([]byte(tt.des), "l", "#", "")); got != tt.want {
+				t.Errorf("removeAltBlocks() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAppendSrc(t *testing.T) {
+	home, _ := os.UserHomeDir()
+	tests := []struct {
+		name string
+		des  string
+		want string
+	}{
+		{
+			name: "added",
+			des:  "x\n",
+			want: "x\n# >>> go-dotfile: df_pub/append/bashrc >>>\nnew\n# <<< go-dotfile: df_pub/append/bashrc <<<\n",
+		},
+		{
+			name: "earlier label relabelled in place",
+			des:  "# >>> go-dotfile: ~/df/df_pub/append/bashrc >>>\nold\n# <<< go-dotfile: ~/df/df_pub/append/bashrc <<<\nx\n",
+			want: "# >>> go-dotfile: df_pub/append/bashrc >>>\nnew\n# <<< go-dotfile: df_pub/append/bashrc <<<\nx\n",
+		},
+		{
+			name: "earlier alternate label removed",
+			des:  "# >>> go-dotfile: ~/df/df_pub/append/bashrc##os.linux >>>\nold\n# <<< go-dotfile: ~/df/df_pub/append/bashrc##os.linux <<<\nx\n",
+			want: "x\n# >>> go-dotfile: df_pub/append/bashrc >>>\nnew\n# <<< go-dotfile: df_pub/append/bashrc <<<\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			root := home + "/df/df_pub/append"
+			got := string(appendSrc([]byte(tt.des), []byte("new\n"), root, root+"/bashrc##os.linux", "#", ""))
+			if got != tt.want {
+				t.Errorf("appendSrc() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBlockLabel(t *testing.T) {
+	tests := []struct {
+		root, srcPath string
+		want          string
+	}{
+		{"/home/u/df/df_pub/append", "/home/u/df/df_pub/append/bashrc", "df_pub/append/bashrc"},
+		{"/home/u/df/df_pub/append/", "/home/u/df/df_pub/append/.config/a##os.linux", "df_pub/append/.config/a"},
+		{"/srv/df_pub/append", "/srv/df_pub/append/bashrc", "df_pub/append/bashrc"},
+		{"/append", "/append/bashrc", "/append/bashrc"},
+	}
+	for _, tt := range tests {
+		if got := blockLabel(tt.root, tt.srcPath); got != tt.want {
+			t.Errorf("blockLabel(%q, %q) = %q, want %q", tt.root, tt.srcPath, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,456 @@
+/*
+Copyright © 2025 John, Sing Dao, Siu <john.sd.siu@gmail.com>
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+
+package lib
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/J-Siu/go-helper/v2/basestruct"
+	"github.com/J-Siu/go-helper/v2/ezlog"
+)
+
+const (
+	DIR_BACKUP       = "backups"
+	FILE_JOURNAL     = "run.ndjson" // one [TypeBackupEntry] per line, appended during the run
+	FILE_JOURNAL_V1  = "run.json"   // journal of earlier versions, read only
+	FILE_ROLLED_BACK = "rolledback" // time of saved rollback of the run
+	RUN_ID_FORMAT    = "20060102-150405.000"
+)
+
+// State of a destination path before it is changed by a run
+type TypeBackupEntry struct {
+	DesPath string      `json:"DesPath"`
+	Exist   bool        `json:"Exist"` // false: created by the run
+	IsDir   bool        `json:"IsDir"` // directory created by the run
+	Link    string      `json:"Link"`  // symlink target
+	Mode    os.FileMode `json:"Mode"`
+	ModTime time.Time   `json:"ModTime"`
+}
+
+// Backup of destination of one saved update run, under DirData/[DIR_BACKUP]/RunID.
+//
+// Files are stored with their absolute destination path, eg. /home/u/.bashrc -> DirData/backups/RunID/home/u/.bashrc
+//
+// Before state of all changed paths, in order of change, is appended to journal [FILE_JOURNAL].
+// Call [TypeBackup.Close] at the end of the run.
+type TypeBackup struct {
+	*basestruct.Base `json:"-"`
+	Dir              string             `json:"-"` // DirData/[DIR_BACKUP]/RunID
+	Entries          []*TypeBackupEntry `json:"Entries"`
+	RolledBack       *time.Time         `json:"-"` // time of saved rollback, nil if not rolled back
+	RunID            string             `json:"RunID"`
+
+	dirData string
+	journal *os.File        // opened on first entry
+	mutex   sync.Mutex      // Save/SaveDir can be called concurrently
+	saved   map[string]bool // paths already in Entries
+}
+
+// Return a new run ID, base on current time
+func NewRunID() string {
+	return time.Now().Format(RUN_ID_FORMAT)
+}
+
+// Run IDs in backup store of [dirData], oldest first
+func BackupRunIDs(dirData string) (runIDs []string, err error) {
+	var entries []os.DirEntry
+	if entries, err = os.ReadDir(path.Join(dirData, DIR_BACKUP)); err == nil {
+		for _, e := range entries {
+			if e.IsDir() {
+				runIDs = append(runIDs, e.Name())
+			}
+		}
+		sort.Strings(runIDs)
+	} else if os.IsNotExist(err) {
+		err = nil
+	}
+	return runIDs, err
+}
+
+// Newest run ID in backup store of [dirData] which changed more than the manifest
+// under [dirState], and is not rolled back, "" if none.
+func BackupLastRunID(dirData, dirState string) (runID string, err error) {
+	var (
+		backup   TypeBackup
+		manifest = path.Join(dirState, FILE_MANIFEST)
+		runIDs   []string
+	)
+	if runIDs, err = BackupRunIDs(dirData); err != nil {
+		return "", err
+	}
+	for i := len(runIDs) - 1; i >= 0; i-- {
+		if backup.New(dirData, runIDs[i]).Read(); backup.Err != nil {
+			return "", backup.Err
+		}
+		if backup.RolledBack != nil {
+			continue
+		}
+		for _, e := range backup.Entries {
+			if e.DesPath != manifest {
+				return runIDs[i], nil
+			}
+		}
+	}
+	return "", nil
+}
+
+func (t *TypeBackup) New(dirData, runID string) *TypeBackup {
+	t.Base = new(basestruct.Base)
+	t.Initialized = true
+	t.MyType = "TypeBackup"
+	t.Dir = path.Join(dirData, DIR_BACKUP, runID)
+	t.Entries = nil
+	t.RolledBack = nil
+	t.dirData = dirData
+	t.RunID = runID
+	t.saved = make(map[string]bool)
+	return t
+}
+
+// Read journal, [FILE_JOURNAL] or [FILE_JOURNAL_V1] of earlier versions, and [FILE_ROLLED_BACK]
+func (t *TypeBackup) Read() *TypeBackup {
+	prefix := t.MyType + ".Read"
+	if !t.CheckErrInit(prefix) {
+		return t
+	}
+	var data []byte
+	if data, t.Err = os.ReadFile(path.Join(t.Dir, FILE_JOURNAL)); t.Err == nil {
+		dec := json.NewDecoder(bytes.NewReader(data))
+		for t.Err == nil && dec.More() {
+			entry := new(TypeBackupEntry)
+			if t.Err = dec.Decode(entry); t.Err == nil {
+				t.Entries = append(t.Entries, entry)
+			}
+		}
+	} else if os.IsNotExist(t.Err) {
+		if data, t.Err = os.ReadFile(path.Join(t.Dir, FILE_JOURNAL_V1)); t.Err == nil {
+			t.Err = json.Unmarshal(data, t)
+		}
+	}
+	for _, e := range t.Entries {
+		t.saved[e.DesPath] = true
+	}
+	if info, e := os.Stat(path.Join(t.Dir, FILE_ROLLED_BACK)); e == nil {
+		modTime := info.ModTime()
+		t.RolledBack = &modTime
+	}
+	return t
+}
+
+// Mark the run as rolled back, see [FILE_ROLLED_BACK]
+func (t *TypeBackup) SetRolledBack() (err error) {
+	now := time.Now()
+	if err = os.WriteFile(path.Join(t.Dir, FILE_ROLLED_BACK), []byte(now.Format(time.RFC3339)+"\n"), 0600); err == nil {
+		t.RolledBack = &now
+	}
+	return err
+}
+
+// Newer runs, not rolled back, which changed any path of this run, run ID -> paths.
+// Rolling back this run first would undo their changes too.
+func (t *TypeBackup) Overlaps() (overlaps map[string][]string, err error) {
+	var (
+		newer  TypeBackup
+		runIDs []string
+	)
+	if runIDs, err = BackupRunIDs(t.dirData); err != nil {
+		return nil, err
+	}
+	overlaps = make(map[string][]string)
+	for _, runID := range runIDs {
+		if runID <= t.RunID {
+			continue
+		}
+		if newer.New(t.dirData, runID).Read(); newer.Err != nil {
+			return nil, newer.Err
+		}
+		if newer.RolledBack != nil {
+			continue
+		}
+		for _, e := range newer.Entries {
+			if t.saved[e.DesPath] {
+				overlaps[runID] = append(overlaps[runID], e.DesPath)
+			}
+		}
+	}
+	return overlaps, nil
+}
+
+// Append [entry] to journal, opening it on first call
+func (t *TypeBackup) write(entry *TypeBackupEntry) (err error) {
+	var data []byte
+	if t.journal == nil {
+		if err = os.MkdirAll(t.Dir, 0700); err == nil {
+			t.journal, err = os.OpenFile(path.Join(t.Dir, FILE_JOURNAL), os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+		}
+	}
+	if err == nil {
+		data, err = json.Marshal(entry)
+	}
+	if err == nil {
+		_, err = t.journal.Write(append(data, '\n'))
+	}
+	return err
+}
+
+// Sync and close journal. Nothing to do if no entry is saved.
+func (t *TypeBackup) Close() (err error) {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	if t.journal != nil {
+		err = t.journal.Sync()
+		if e := t.journal.Close(); err == nil {
+			err = e
+		}
+		t.journal = nil
+	}
+	return err
+}
+
+// Backup path of [desPath]
+func (t *TypeBackup) FilePath(desPath string) string {
+	return path.Join(t.Dir, desPath)
+}
+
+// Destination paths of backup files, sorted
+func (t *TypeBackup) Files() (desPaths []string, err error) {
+	err = filepath.WalkDir(t.Dir, func(p string, d os.DirEntry, e error) error {
+		if e == nil && d.Type().IsRegular() {
+			var rel string
+			if rel, e = filepath.Rel(t.Dir, p); e == nil && rel != FILE_JOURNAL && rel != FILE_JOURNAL_V1 && rel != FILE_ROLLED_BACK {
+				desPaths = append(desPaths, "/"+rel)
+			}
+		}
+		return e
+	})
+	return desPaths, err
+}
+
+// Record state of [desPath] in journal, and copy regular file into backup,
+// keeping permission and modification time. Call before [desPath] is changed.
+//
+// Only the first call of a [desPath] counts, so it is the state before the run.
+// Safe for concurrent use with different [desPath].
+func (t *TypeBackup) Save(desPath string) (err error) {
+	prefix := t.MyType + ".Save"
+	// reserve [desPath], copy is done without lock
+	t.mutex.Lock()
+	if t.saved[desPath] {
+		t.mutex.Unlock()
+		return nil
+	}
+	t.saved[desPath] = true
+	t.mutex.Unlock()
+
+	var (
+		entry = TypeBackupEntry{DesPath: desPath}
+		info  os.FileInfo
+	)
+	if info, err = os.Lstat(desPath); err == nil {
+		entry.Exist = true
+		entry.Mode = info.Mode()
+		entry.ModTime = info.ModTime()
+		switch {
+		case info.Mode()&os.ModeSymlink != 0:
+			entry.Link, err = os.Readlink(desPath)
+		case info.Mode().IsRegular():
+			bakPath := t.FilePath(desPath)
+			if err = os.MkdirAll(path.Dir(bakPath), 0700); err == nil {
+				err = copyFile(desPath, bakPath)
+			}
+		}
+	} else if os.IsNotExist(err) {
+		err = nil
+	}
+
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	if err == nil {
+		t.Entries = append(t.Entries, &entry)
+		err = t.write(&entry)
+	} else {
+		delete(t.saved, desPath)
+	}
+	ezlog.Debug().N(prefix).N(desPath).M(entry.Exist).Out()
+	return err
+}
+
+// Record directory [dirPath] in journal. Call before [dirPath] is created.
+func (t *TypeBackup) SaveDir(dirPath string) (err error) {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	if !t.saved[dirPath] {
+		entry := TypeBackupEntry{DesPath: dirPath, IsDir: true}
+		t.saved[dirPath] = true
+		t.Entries = append(t.Entries, &entry)
+		err = t.write(&entry)
+	}
+	return err
+}
+
+// Restore all files in backup to their destination
+//   - [save] = true: save, false: dry run
+func (t *TypeBackup) Restore(save bool) (records TypeDotfileRecords, err error) {
+	var desPaths []string
+	if desPaths, err = t.Files(); err != nil {
+		return nil, err
+	}
+	for _, desPath := range desPaths {
+		var (
+			bakInfo os.FileInfo
+			desInfo os.FileInfo
+			record  = TypeDotfileRecord{
+				DesPath:      desPath,
+				FileProcMode: COPY,
+				Save:         save,
+				SrcMode:      COPY,
+				SrcPath:      t.FilePath(desPath),
+			}
+		)
+		if bakInfo, err = os.Stat(record.SrcPath); err != nil {
+			break
+		}
+		record.SrcInfo = &bakInfo
+		if desInfo, err = os.Lstat(desPath); err == nil {
+			record.DesInfo = &desInfo
+		}
+		err = nil // destination may not exist
+		if save {
+			if err = os.MkdirAll(path.Dir(desPath), os.ModePerm); err == nil {
+				err = copyFile(record.SrcPath, desPath)
+			}
+		}
+		if err != nil {
+			break
+		}
+		records = append(records, &record)
+	}
+	return records, err
+}
+
+// Put destination back to its state before the run, undoing journal entries in reverse order
+//   - [save] = true: save, false: dry run
+//
+// All entries are processed, errors are joined. A saved rollback without error marks
+// the run rolled back, see [TypeBackup.SetRolledBack]. Check [TypeBackup.Overlaps] before.
+func (t *TypeBackup) Rollback(save bool) (records TypeDotfileRecords, err error) {
+	for i := len(t.Entries) - 1; i >= 0; i-- {
+		record, e := t.rollbackEntry(t.Entries[i], save)
+		if e == nil {
+			records = append(records, record)
+		} else {
+			err = errors.Join(err, errors.New(t.Entries[i].DesPath+": "+e.Error()))
+		}
+	}
+	if err == nil && save {
+		err = t.SetRolledBack()
+	}
+	return records, err
+}
+
+// Undo change of a journal [entry]
+//   - created file/directory: REMOVE
+//   - symlink: LINK to previous target
+//   - regular file: COPY from backup
+func (t *TypeBackup) rollbackEntry(entry *TypeBackupEntry, save bool) (record *TypeDotfileRecord, err error) {
+	var (
+		bakInfo os.FileInfo
+		desInfo os.FileInfo
+		e       error
+	)
+	record = &TypeDotfileRecord{
+		DesPath: entry.DesPath,
+		Save:    save,
+		SrcMode: COPY,
+	}
+	if desInfo, e = os.Lstat(entry.DesPath); e == nil {
+		record.DesInfo = &desInfo
+	}
+	switch {
+	case !entry.Exist:
+		record.FileProcMode = REMOVE
+		if e != nil {
+			record.FileProcMode = SKIP // already gone
+		} else if save {
+			err = os.Remove(entry.DesPath)
+		}
+	case entry.Link != "":
+		record.FileProcMode = LINK
+		record.LinkState = LINK_NEW
+		record.SrcMode = LINK
+		record.SrcPath = entry.Link
+		if e == nil {
+			record.LinkState = LINK_FILE
+			if desInfo.Mode()&os.ModeSymlink != 0 {
+				record.DesLink, _ = os.Readlink(entry.DesPath)
+				record.LinkState = LINK_WRONG
+			}
+		}
+		if record.DesLink == entry.Link {
+			record.FileProcMode = SKIP
+		} else if save {
+			if e == nil {
+				err = os.Remove(entry.DesPath)
+			}
+			if err == nil {
+				err = os.Symlink(entry.Link, entry.DesPath)
+			}
+		}
+	case entry.Mode.IsRegular():
+		record.FileProcMode = COPY
+		record.SrcPath = t.FilePath(entry.DesPath)
+		if bakInfo, err = os.Stat(record.SrcPath); err == nil {
+			record.SrcInfo = &bakInfo
+			if save {
+				err = copyFile(record.SrcPath, entry.DesPath)
+			}
+		}
+	default:
+		record.FileProcMode = SKIP
+	}
+	return record, err
+}
+
+// Copy [src] to [des], with permission and modification time.
+// A symlink at [des] is replaced, not followed.
+func copyFile(src, des string) (err error) {
+	var (
+		data []byte
+		info os.FileInfo
+	)
+	if info, err = os.Stat(src); err == nil {
+		data, err = os.ReadFile(src)
+	}
+	if err == nil {
+		err = writeAtomicTime(des, data, info.Mode().Perm(), info.ModTime())
+	}
+	return err
+}
@@ -0,0 +1,101 @@
+/*
+Copyright © 2025 John, Sing Dao, Siu <john.sd.siu@gmail.com>
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+
+package lib
+
+import (
+	"bytes"
+	"io"
+	"os"
+
+	"github.com/J-Siu/go-helper/v2/file"
+)
+
+// Compare values, how COPY decides destination is same as source
+const (
+	COMPARE_BYTES      = "bytes"      // content, byte by byte
+	COMPARE_HASH       = "hash"       // sha256 of content, cached in manifest
+	COMPARE_MTIME_SIZE = "mtime-size" // default, modification time and size
+)
+
+// Check if COPY destination [desPath] is same as [srcPath], by t.Compare
+func (t *TypeDotfile) sameFile(srcPath, desPath string, srcInfo, desInfo os.FileInfo) (same bool, err error) {
+	switch t.Compare {
+	case COMPARE_BYTES, COMPARE_HASH:
+		if !desInfo.Mode().IsRegular() || srcInfo.Size() != desInfo.Size() {
+			return false, nil
+		}
+		if t.Compare == COMPARE_BYTES {
+			return sameContent(srcPath, desPath)
+		}
+		var (
+			desHash, srcHash string
+			entry            = new(TypeManifestEntry)
+		)
+		if t.Manifest != nil {
+			if e := t.Manifest.Entry(desPath, srcPath); e != nil {
+				entry = e
+			}
+		}
+		if srcHash, err = cachedHash(srcPath, srcInfo, entry.SrcHash, entry.SrcSize, entry.SrcModTime); err == nil {
+			desHash, err = cachedHash(desPath, desInfo, entry.DesHash, entry.DesSize, entry.DesModTime)
+		}
+		return err == nil && srcHash == desHash, err
+	default:
+		return file.FileSame(srcPath, desPath), nil
+	}
+}
+
+// Compare content of [path1] and [path2]
+func sameContent(path1, path2 string) (same bool, err error) {
+	var f1, f2 *os.File
+	if f1, err = os.Open(path1); err != nil {
+		return false, err
+	}
+	defer f1.Close()
+	if f2, err = os.Open(path2); err != nil {
+		return false, err
+	}
+	defer f2.Close()
+	var (
+		buf1 = make([]byte, 64*1024)
+		buf2 = make([]byte, 64*1024)
+	)
+	for {
+		n1, e1 := io.ReadFull(f1, buf1)
+		n2, e2 := io.ReadFull(f2, buf2)
+		if !bytes.Equal(buf1[:n1], buf2[:n2]) {
+			return false, nil
+		}
+		end1 := e1 == io.EOF || e1 == io.ErrUnexpectedEOF
+		end2 := e2 == io.EOF || e2 == io.ErrUnexpectedEOF
+		if e1 != nil && !end1 {
+			return false, e1
+		}
+		if e2 != nil && !end2 {
+			return false, e2
+		}
+		if end1 || end2 {
+			return end1 && end2, nil
+		}
+	}
+}
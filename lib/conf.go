@@ -24,29 +24,62 @@ package lib
 
 import (
 	"os"
+	"path"
+	"strings"
 
 	"github.com/J-Siu/go-helper/v2/basestruct"
 	"github.com/J-Siu/go-helper/v2/ezlog"
 	"github.com/J-Siu/go-helper/v2/file"
+	"github.com/go-viper/mapstructure/v2"
 	"github.com/spf13/viper"
 )
 
+// Legacy config file, searched after [CONF_NAME] in XDG config directory
 var Default = TypeConf{
 	FileConf: "$HOME/.config/go-dotfile.json",
 }
 
+// Config file name, without extension, in $XDG_CONFIG_HOME/go-dotfile
+const CONF_NAME = "config"
+
+// Config file formats by extension. Other extensions are read as json.
+var ConfExts = []string{".json", ".yaml", ".yml", ".toml"}
+
 type TypeConf struct {
 	*basestruct.Base
 
-	DirAP    []string `json:"DirAP,omitempty"`
-	DirCP    []string `json:"DirCP,omitempty"`
-	DirDest  string   `json:"DirDest,omitempty"`
-	DirSkip  []string `json:"DirSkip,omitempty"`
-	FileConf string   `json:"FileConf,omitempty"`
-	FileSkip []string `json:"FileSkip,omitempty"`
+	Comment    map[string]string       `json:"Comment,omitempty"`
+	Compare    string                  `json:"Compare,omitempty"` // COPY comparison: mtime-size(default), hash, bytes
+	Data       map[string]any          `json:"Data,omitempty"`    // template user variables
+	DirAP      []string                `json:"DirAP,omitempty"`
+	DirCP      []string                `json:"DirCP,omitempty"`
+	DirData    string                  `json:"DirData,omitempty"`
+	DirDest    string                  `json:"DirDest,omitempty"`
+	DirLN      []string                `json:"DirLN,omitempty"`
+	DirScripts string                  `json:"DirScripts,omitempty"` // scripts directory in root of source directories, not deployed
+	DirSkip    []string                `json:"DirSkip,omitempty"`
+	DirState   string                  `json:"DirState,omitempty"`
+	FileConf   string                  `json:"FileConf,omitempty"`
+	FileSkip   []string                `json:"FileSkip,omitempty"`
+	Hooks      TypeHooks               `json:"Hooks"`              // commands run by saved update
+	Identity   string                  `json:"Identity,omitempty"` // age identity file, for encrypted files
+	Include    []string                `json:"Include,omitempty"`  // files merged before this one, glob, relative to including file
+	Origin     map[string]string       `json:"-"`                  // key path -> file the value came from
+	Profile    string                  `json:"Profile,omitempty"`  // selected profiles, comma separated
+	Profiles   map[string]*TypeProfile `json:"Profiles,omitempty"`
+	Recipients []string                `json:"Recipients,omitempty"` // extra age recipients of encrypt
+	SkipMatch  string                  `json:"SkipMatch,omitempty"`  // DirSkip/FileSkip matching: glob(default), substring
+	Symlink    string                  `json:"Symlink,omitempty"`    // COPY/APPEND to symlink destination: follow(default), replace
+
+	files []*confFile // files read, for unknown keys
 }
 
-func (t *TypeConf) New() {
+// Read config file t.FileConf, or the default one if empty, apply profiles t.Profile, and validate.
+// Error is in t.Err:
+//   - [ErrConfNotFound]: config file or include not found
+//   - [ErrConfInvalid]: [TypeConfProblems] of all problems found
+//   - [ErrDestMissing]: DirDest does not exist
+func (t *TypeConf) New() *TypeConf {
 	t.Base = new(basestruct.Base)
 	t.Initialized = true
 	t.MyType = "TypeConf"
@@ -55,47 +88,117 @@ func (t *TypeConf) New() {
 	t.setDefault()
 	ezlog.Debug().N(prefix).N("Default").Lm(t).Out()
 
+	profile := t.Profile // command line/env over config file
 	t.readFileConf()
+	if profile != "" {
+		t.Profile = profile
+	}
 	ezlog.Debug().N(prefix).N("Raw").Lm(t).Out()
 
-	t.expand()
-	ezlog.Debug().N(prefix).N("Expand").Lm(t).Out()
-
-	// Check DirDest
-	if !file.IsDir(t.DirDest) {
-		ezlog.Err().N(prefix).N("DirDest does not exist").M(t.DirDest).Out()
-		os.Exit(1)
+	if t.Err == nil {
+		if problems := t.Validate(); problems != nil {
+			t.Err = TypeConfProblems(problems)
+		}
+	}
+	if t.Err == nil {
+		t.Err = t.applyProfile()
+		ezlog.Debug().N(prefix).N("Profile").M(t.Profile).Out()
 	}
+	if t.Err == nil {
+		t.expand()
+		ezlog.Debug().N(prefix).N("Expand").Lm(t).Out()
+		// Check DirDest
+		if !file.IsDir(t.DirDest) {
+			t.Err = &TypeError{Path: t.DirDest, Sentinel: ErrDestMissing}
+		}
+	}
+	return t
 }
 
 func (t *TypeConf) readFileConf() {
-	t.MyType = "TypeConf"
-	prefix := t.MyType + ".readFileConf"
-
-	viper.SetConfigType("json")
-	viper.SetConfigFile(file.TildeEnvExpand(t.FileConf))
-	viper.AutomaticEnv()
-	if t.Err = viper.ReadInConfig(); t.Err == nil {
-		t.Err = viper.Unmarshal(&t)
-	} else {
-		ezlog.Debug().N(prefix).M(t.Err).Out()
-		os.Exit(1)
+	var (
+		merged = make(map[string]any)
+		v      = viper.New()
+	)
+	t.Origin = make(map[string]string)
+	t.files = nil
+	t.Err = t.readFile(file.TildeEnvExpand(t.FileConf), merged, make(map[string]bool))
+	// keys of Comment and Data as is, see [confRawKeys]
+	raw := make(map[string]any)
+	for _, name := range confRawKeys {
+		key := strings.ToLower(name)
+		raw[name] = merged[key]
+		delete(merged, key)
+	}
+	if t.Err == nil {
+		t.Err = v.MergeConfigMap(merged)
+	}
+	if t.Err == nil {
+		v.AutomaticEnv()
+		t.Err = v.Unmarshal(&t)
+	}
+	if t.Err == nil {
+		t.Err = mapstructure.Decode(raw, t)
 	}
 }
 
 // Should be called before reading config file
 func (t *TypeConf) setDefault() {
+	home, _ := os.UserHomeDir()
 	if t.FileConf == "" {
-		t.FileConf = Default.FileConf
+		t.FileConf = confSearch(home)
+	}
+	if t.Profile == "" {
+		t.Profile = os.Getenv(ENV_PROFILE)
+	}
+	if t.DirScripts == "" {
+		t.DirScripts = DIR_SCRIPTS
+	}
+	t.DirDest = home
+	t.DirData = xdgDir("XDG_DATA_HOME", path.Join(home, ".local", "share"))
+	t.DirState = xdgDir("XDG_STATE_HOME", path.Join(home, ".local", "state"))
+	t.Identity = path.Join(xdgDir("XDG_CONFIG_HOME", path.Join(home, ".config")), FILE_IDENTITY)
+}
+
+// First existing config file in $XDG_CONFIG_HOME/go-dotfile/[CONF_NAME].{json,yaml,yml,toml},
+// then [Default].FileConf. Return [Default].FileConf if none exists.
+func confSearch(home string) string {
+	dir := xdgDir("XDG_CONFIG_HOME", path.Join(home, ".config"))
+	for _, ext := range ConfExts {
+		if p := path.Join(dir, CONF_NAME+ext); file.IsRegularFile(p) {
+			return p
+		}
+	}
+	return Default.FileConf
+}
+
+// Config type of [filePath] by extension, json if unknown
+func confType(filePath string) string {
+	ext := strings.ToLower(path.Ext(filePath))
+	for _, e := range ConfExts {
+		if ext == e {
+			return ext[1:]
+		}
+	}
+	return "json"
+}
+
+// Return go-dotfile directory under XDG [env], or under [dirDefault] if [env] not set
+func xdgDir(env, dirDefault string) string {
+	if dir := os.Getenv(env); dir != "" {
+		return path.Join(dir, "go-dotfile")
 	}
-	t.DirDest, _ = os.UserHomeDir()
+	return path.Join(dirDefault, "go-dotfile")
 }
 
 func (t *TypeConf) expand() {
+	t.DirData = file.TildeEnvExpand(t.DirData)
 	t.DirDest = file.TildeEnvExpand(t.DirDest)
+	t.DirState = file.TildeEnvExpand(t.DirState)
 	t.FileConf = file.TildeEnvExpand(t.FileConf)
+	t.Identity = file.TildeEnvExpand(t.Identity)
 
-	strArrays := [][]string{t.DirAP, t.DirCP, t.DirSkip, t.FileSkip}
+	strArrays := [][]string{t.DirAP, t.DirCP, t.DirLN, t.DirSkip, t.FileSkip}
 	for _, arr := range strArrays {
 		for i := range arr {
 			arr[i] = file.TildeEnvExpand(arr[i])
@@ -0,0 +1,116 @@
+/*
+Copyright © 2025 John, Sing Dao, Siu <john.sd.siu@gmail.com>
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+
+package lib
+
+import (
+	"bytes"
+	"os"
+	"strings"
+
+	"github.com/pmezard/go-difflib/difflib"
+)
+
+// Unified diff of destination, current -> after processing
+//   - COPY/APPEND: content, and mode if changed
+//   - CHMOD: mode
+//   - others: empty
+//
+// Empty if content and mode are same, eg. COPY of a file with only modification time changed.
+// Diff starts with line "diff DesPath SrcPath". Record must be from [TypeConf.ProcessData].
+func (t *TypeDotfileRecord) Diff() (diff string, err error) {
+	var (
+		cur     []byte
+		desMode os.FileMode
+		header  string
+	)
+	switch t.FileProcMode {
+	case APPEND, CHMOD, COPY:
+	default:
+		return "", nil
+	}
+	if t.DesInfo != nil {
+		desMode = (*t.DesInfo).Mode()
+		if t.FileProcMode != CHMOD {
+			cur, err = os.ReadFile(t.DesPath)
+		}
+	}
+	if err == nil && t.DesInfo != nil && desMode == t.deployMode() && t.FileProcMode != CHMOD && bytes.Equal(cur, t.Data) {
+		return "", nil
+	}
+	if err == nil && (t.DesInfo == nil || desMode != t.deployMode()) {
+		if t.DesInfo == nil {
+			header = "new mode " + t.deployMode().String() + "\n"
+		} else {
+			header = "old mode " + desMode.String() + "\n" +
+				"new mode " + t.deployMode().String() + "\n"
+		}
+	}
+	if err == nil && t.FileProcMode != CHMOD {
+		if isBinary(cur) || isBinary(t.Data) {
+			diff = "Binary files " + t.DesPath + " and " + t.SrcPath + " differ\n"
+		} else {
+			diff, err = difflib.GetUnifiedDiffString(difflib.UnifiedDiff{
+				A:        splitLines(cur),
+				B:        splitLines(t.Data),
+				FromFile: t.DesPath,
+				ToFile:   t.SrcPath,
+				Context:  3,
+			})
+		}
+	}
+	if err == nil {
+		diff = "diff " + t.DesPath + " " + t.SrcPath + "\n" + header + diff
+	}
+	return diff, err
+}
+
+// Count of added and deleted lines in unified [diff]
+func DiffStat(diff string) (add, del int) {
+	var hunk bool // file headers are before first hunk
+	for _, line := range strings.Split(diff, "\n") {
+		switch {
+		case strings.HasPrefix(line, "@@"):
+			hunk = true
+		case !hunk:
+		case strings.HasPrefix(line, "+"):
+			add++
+		case strings.HasPrefix(line, "-"):
+			del++
+		}
+	}
+	return add, del
+}
+
+// Lines of [data], with "\n". Unlike difflib.SplitLines, no empty line after last "\n".
+func splitLines(data []byte) []string {
+	lines := strings.SplitAfter(string(data), "\n")
+	if lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+	return lines
+}
+
+// Content with NUL byte in first 8000 bytes is binary, same as git
+func isBinary(data []byte) bool {
+	return bytes.IndexByte(data[:min(len(data), 8000)], 0) >= 0
+}
@@ -23,13 +23,14 @@ THE SOFTWARE.
 package lib
 
 import (
+	"bytes"
+	"errors"
 	"os"
 	"path"
+	"path/filepath"
 	"strings"
-	"time"
 
 	"github.com/J-Siu/go-helper/v2/basestruct"
-	"github.com/J-Siu/go-helper/v2/errs"
 	"github.com/J-Siu/go-helper/v2/ezlog"
 	"github.com/J-Siu/go-helper/v2/file"
 	"github.com/J-Siu/go-helper/v2/str"
@@ -42,17 +43,56 @@ const (
 	APPEND FileProcMode = iota
 	CHMOD
 	COPY
+	LINK
+	REMOVE
 	SKIP
 )
 
+// Mode as name in JSON, as number changes when mode is added
+func (m FileProcMode) MarshalText() ([]byte, error) {
+	return []byte(m.String()), nil
+}
+
+func (m *FileProcMode) UnmarshalText(text []byte) error {
+	for i := APPEND; i <= SKIP; i++ {
+		if i.String() == string(text) {
+			*m = i
+			return nil
+		}
+	}
+	return errors.New("invalid FileProcMode: " + string(text))
+}
+
+// Destination state of a LINK mode file
+type LinkState int8
+
+const (
+	LINK_NONE     LinkState = iota // not a LINK record
+	LINK_DANGLING                  // symlink with missing target
+	LINK_FILE                      // regular file in the way
+	LINK_NEW                       // destination does not exist
+	LINK_OK                        // symlink to source already
+	LINK_WRONG                     // symlink to other target
+)
+
 // Property struct to initialize TypeDotfile
 type TypeDotfileProperty struct {
-	DirDest  *string      `json:"DirDest"`  // destination directory
-	DirSkip  *[]string    `json:"DirSkip"`  // substrings to filter out directories in DirSrc tree
-	DirSrc   *string      `json:"DirSrc"`   // source directory
-	FileSkip *[]string    `json:"FileSkip"` // substrings to filter out files in DirSrc tree
-	Mode     FileProcMode `json:"Mode"`     // COPY / APPEND
-	Save     bool         `json:"Save"`     // true: save, false: dry run
+	Age        *TypeAge           `json:"-"`          // COPY/APPEND: identity for decrypting [AGE_EXT] files, nil: no decryption
+	Backup     *TypeBackup        `json:"Backup"`     // backup destination before overwrite, nil: no backup
+	Comment    *map[string]string `json:"Comment"`    // APPEND: comment syntax by extension, see [CommentDefault]
+	Compare    string             `json:"Compare"`    // COPY: [COMPARE_MTIME_SIZE](default) / [COMPARE_HASH] / [COMPARE_BYTES]
+	DirDest    *string            `json:"DirDest"`    // destination directory
+	DirScripts string             `json:"DirScripts"` // scripts directory in root of DirSrc, not deployed, "" if none
+	DirSkip    *[]string          `json:"DirSkip"`    // patterns to filter out directories in DirSrc tree
+	DirSrc     *string            `json:"DirSrc"`     // source directory
+	FileSkip   *[]string          `json:"FileSkip"`   // patterns to filter out files in DirSrc tree
+	KeepData   bool               `json:"KeepData"`   // COPY/APPEND: keep destination content in record Data, eg. for diff
+	Manifest   *TypeManifest      `json:"-"`          // COMPARE_HASH: cached hashes, nil: no cache
+	Mode       FileProcMode       `json:"Mode"`       // COPY / APPEND / LINK
+	Save       bool               `json:"Save"`       // true: save, false: dry run
+	SkipMatch  string             `json:"SkipMatch"`  // DirSkip/FileSkip matching, [SKIP_MATCH_GLOB](default) / [SKIP_MATCH_SUBSTRING]
+	Symlink    string             `json:"Symlink"`    // COPY/APPEND: symlink destination, [SYMLINK_FOLLOW](default) / [SYMLINK_REPLACE]
+	Template   *TypeTemplateData  `json:"-"`          // COPY/APPEND: data for rendering [TMPL_EXT] files
 }
 
 // Property struct to process Dotfile directories and files
@@ -82,145 +122,336 @@ func (t *TypeDotfile) New(property *TypeDotfileProperty) *TypeDotfile {
 	return t
 }
 
-func (t *TypeDotfile) Run() {
-	prefix := t.MyType + ".Run"
-	var e error
-	// cd to simplify path handling
-	if t.Err = os.Chdir(*t.DirSrc); t.Err == nil {
-		t.Dirs, t.Files = t.getDirFile(".")
-		ezlog.Debug().N(prefix).N("Dirs").Lm(t.Dirs).Out()
-		ezlog.Debug().N(prefix).N("Files").Lm(t.Files).Out()
-		// create dirs on 'save' mode
-		if t.Save && t.Dirs != nil {
-			for _, fileDir := range *t.Dirs {
-				if t.Err = dirCreateHidden(fileDir, *t.DirDest); t.Err == nil {
-					errs.Queue(prefix, t.Err)
-				}
+// Process files in DirSrc, one by one. Errors are joined in t.Err:
+//   - DirSrc: [ErrSrcUnreadable]
+//   - individual files: [ErrFileFailed]
+func (t *TypeDotfile) Run() *TypeDotfile {
+	tasks, errList := t.prepare()
+	records, e := runTasks(tasks, 1)
+	t.Records = records
+	t.Err = errors.Join(append(errList, e...)...)
+	return t
+}
+
+// Get files in DirSrc, create directories on save, and return a task for each file.
+// Error of DirSrc is [ErrSrcUnreadable], no task is returned.
+// Errors of creating directories are each an [ErrFileFailed].
+func (t *TypeDotfile) prepare() (tasks []*dotfileTask, errList []error) {
+	prefix := t.MyType + ".prepare"
+	var err error
+	if t.Dirs, t.Files, err = t.getDirFile(*t.DirSrc); err != nil {
+		return nil, []error{&TypeError{Err: err, Path: *t.DirSrc, Sentinel: ErrSrcUnreadable}}
+	}
+	ezlog.Debug().N(prefix).N("Dirs").Lm(t.Dirs).Out()
+	ezlog.Debug().N(prefix).N("Files").Lm(t.Files).Out()
+	// create dirs on 'save' mode
+	if t.Save && t.Dirs != nil {
+		for _, fileDir := range *t.Dirs {
+			if e := dirCreateHidden(fileDir, *t.DirDest, t.Backup); e != nil {
+				errList = append(errList, &TypeError{Err: e, Path: path.Join(*t.DirDest, hiddenPath(fileDir)), Sentinel: ErrFileFailed})
 			}
 		}
-		// Append/Copy files
-		if t.Files != nil {
-			for _, filepathSrc := range *t.Files {
-				filepathDest := path.Join(*t.DirDest, hiddenPath(filepathSrc))
-				e = t.processFile(path.Join(*t.DirSrc, filepathSrc), filepathDest)
-				errs.Queue(prefix, e)
+	}
+	// Append/Copy files
+	if t.Files != nil {
+		for _, filepathSrc := range *t.Files {
+			// alternate is deployed under its base name
+			task := dotfileTask{
+				df:      t,
+				desPath: path.Join(*t.DirDest, hiddenPath(trimAlt(filepathSrc))),
+				srcPath: path.Join(*t.DirSrc, filepathSrc),
+			}
+			if t.Mode != LINK {
+				// encrypted file and template are deployed without their extension
+				task.desPath = trimTemplate(trimEncrypted(task.desPath))
 			}
+			tasks = append(tasks, &task)
 		}
 	}
+	return tasks, errList
 }
 
 // Process file base on Mode(append|copy)
 //   - [srcPath] = source file path
 //   - [desPath] = destination file path
 //
-// Not using TypeDotfile.Err
-func (t *TypeDotfile) processFile(srcPath, desPath string) (err error) {
+// A template or encrypted source is rendered/decrypted first, and compared by
+// content, as its size and modification time are not those of the output.
+// Decrypted file is deployed without group and other permission.
+//
+// Not using TypeDotfile.Err, safe to run concurrently for different [desPath]
+func (t *TypeDotfile) processFile(srcPath, desPath string) (*TypeDotfileRecord, error) {
 	// prefix := t.MyType + ".processFile"
 
 	var (
-		data       []byte
-		desInfo    os.FileInfo
-		desModTime time.Time
-		srcInfo    os.FileInfo
+		data    []byte
+		desInfo os.FileInfo
+		err     error
+		srcInfo os.FileInfo
+		srcMode os.FileMode // permission of destination
 
 		record = TypeDotfileRecord{
 			DesPath:      desPath,
 			FileProcMode: t.Mode,
+			Save:         t.Save,
+			SrcMode:      t.Mode,
 			SrcPath:      srcPath,
 		}
 	)
+	_, record.Variant = altSplit(srcPath)
 
 	// Get File info before actual processing
 	desInfo, err = os.Stat(desPath)
 	if err == nil {
-		desModTime = desInfo.ModTime()
 		record.DesInfo = &desInfo
 	}
 	err = nil // Resetting err, as dest may not exist.
 
 	srcInfo, err = os.Stat(srcPath)
 	record.SrcInfo = &srcInfo
+	srcMode = record.deployMode()
 
-	if record.FileProcMode == COPY && file.FileSame(srcPath, desPath) {
-		record.FileProcMode = SKIP
+	// Symlink destination is never same, if it is to be replaced
+	replaceLink := t.Symlink == SYMLINK_REPLACE && isSymlink(desPath)
+
+	if err == nil && record.FileProcMode == COPY && !isTransformed(srcPath) && record.DesInfo != nil && !replaceLink {
+		var same bool
+		if same, err = t.sameFile(srcPath, desPath, srcInfo, desInfo); same {
+			record.FileProcMode = SKIP
+		}
 	}
 
-	// Append only compare modTime
-	if record.FileProcMode == APPEND && srcInfo.ModTime().Equal(desModTime) {
-		record.FileProcMode = SKIP
+	// Append compare destination content with block of source replaced
+	if err == nil && record.FileProcMode == APPEND {
+		var desData, srcData []byte
+		if srcData, err = t.readSrc(srcPath); err == nil && record.DesInfo != nil {
+			desData, err = os.ReadFile(desPath)
+		}
+		if err == nil {
+			open, close := commentOf(desPath, t.Comment)
+			record.BlockLabel = blockLabel(*t.DirSrc, srcPath)
+			data = appendSrc(desData, srcData, *t.DirSrc, srcPath, open, close)
+			if record.DesInfo != nil && bytes.Equal(data, desData) && !replaceLink {
+				record.FileProcMode = SKIP
+			}
+		}
+	}
+
+	// Read source file
+	if err == nil && record.FileProcMode == COPY {
+		data, err = t.readSrc(srcPath)
+	}
+	if err == nil && record.FileProcMode == COPY && isTransformed(srcPath) && record.DesInfo != nil && !replaceLink {
+		var desData []byte
+		if desData, err = os.ReadFile(desPath); err == nil && bytes.Equal(data, desData) {
+			record.FileProcMode = SKIP
+		}
+	}
+	if record.FileProcMode == APPEND || record.FileProcMode == COPY {
+		record.DataSize = int64(len(data))
+		if t.KeepData {
+			record.Data = data
+		}
 	}
 
 	// Chmod only if file mode is different, as chmod does not change modTime
-	if record.FileProcMode == SKIP && srcInfo.Mode() != desInfo.Mode() {
+	if record.FileProcMode == SKIP && srcMode != desInfo.Mode() {
 		record.FileProcMode = CHMOD
 	}
 
-	if record.FileProcMode != SKIP && t.Save {
-		if record.FileProcMode != CHMOD {
-			// Read source file
-			if err == nil {
-				data, err = os.ReadFile(srcPath)
-			}
-			//
-			if err == nil {
-				if record.FileProcMode == APPEND {
-					// APPEND: add newline to destination file
-					b := []byte("\n")
-					if err = file.AppendByte(desPath, &b); err == nil {
-						err = file.AppendByte(desPath, &data)
-					}
-				} else { // COPY
-					err = file.WriteByte(desPath, &data, srcInfo.Mode())
-				}
+	if err == nil && record.FileProcMode != SKIP && t.Save {
+		writePath := desPath
+		if t.Symlink != SYMLINK_REPLACE {
+			writePath, err = followLink(desPath)
+		}
+		// Backup destination before change
+		if err == nil && t.Backup != nil {
+			err = t.Backup.Save(writePath)
+		}
+		if err == nil {
+			switch record.FileProcMode {
+			case CHMOD:
+				err = os.Chmod(writePath, srcMode)
+			case COPY:
+				// data is source, with source modTime
+				err = writeAtomicTime(writePath, data, srcMode, srcInfo.ModTime())
+			default:
+				// data is destination with block replaced/added
+				err = writeAtomic(writePath, data, srcMode)
 			}
-			// Set dest modTime
-			if err == nil {
-				err = os.Chtimes(desPath, srcInfo.ModTime(), srcInfo.ModTime())
+		}
+	}
+
+	if err != nil {
+		return nil, err
+	}
+	return &record, nil
+}
+
+// Process file in LINK mode
+//   - [srcPath] = source file path, used as link target
+//   - [desPath] = destination file path, location of the symlink
+//
+// An existing link pointing to [srcPath] is a SKIP. A wrong, dangling link or
+// a regular file in the way is replaced on save. A directory in the way is an error.
+//
+// Not using TypeDotfile.Err, safe to run concurrently for different [desPath]
+func (t *TypeDotfile) processLink(srcPath, desPath string) (*TypeDotfileRecord, error) {
+	var (
+		desInfo os.FileInfo
+		err     error
+		srcInfo os.FileInfo
+
+		record = TypeDotfileRecord{
+			DesPath:      desPath,
+			FileProcMode: LINK,
+			LinkState:    LINK_NEW,
+			Save:         t.Save,
+			SrcMode:      LINK,
+		}
+	)
+	_, record.Variant = altSplit(srcPath)
+
+	// Link target must be absolute, as link and source are in different trees
+	srcPath, err = filepath.Abs(srcPath)
+	record.SrcPath = srcPath
+	if err == nil {
+		srcInfo, err = os.Stat(srcPath)
+		record.SrcInfo = &srcInfo
+	}
+
+	// Lstat, as Stat follows the link
+	if err == nil {
+		var e error
+		if desInfo, e = os.Lstat(desPath); e == nil {
+			record.DesInfo = &desInfo
+			switch {
+			case desInfo.Mode()&os.ModeSymlink != 0:
+				record.DesLink, err = os.Readlink(desPath)
+				record.LinkState = linkState(record.DesLink, srcPath, desPath)
+			case desInfo.Mode().IsRegular():
+				record.LinkState = LINK_FILE
+			default:
+				err = errors.New("not a file or symlink")
 			}
+		} else if !os.IsNotExist(e) {
+			err = e
+		}
+	}
+
+	if record.LinkState == LINK_OK {
+		record.FileProcMode = SKIP
+	}
+
+	if err == nil && record.FileProcMode == LINK && t.Save {
+		if t.Backup != nil {
+			err = t.Backup.Save(desPath)
+		}
+		if err == nil && record.LinkState != LINK_NEW {
+			err = os.Remove(desPath)
 		}
-		// Set dest permission
 		if err == nil {
-			err = os.Chmod(desPath, srcInfo.Mode())
+			err = os.Symlink(srcPath, desPath)
 		}
 	}
 
-	if err == nil {
-		t.Records = append(t.Records, &record)
+	if err != nil {
+		return nil, err
 	}
+	return &record, nil
+}
 
-	return err
+// Read source file, decrypted if it is encrypted, then rendered if it is a template
+func (t *TypeDotfile) readSrc(srcPath string) (data []byte, err error) {
+	if data, err = os.ReadFile(srcPath); err == nil && isEncrypted(srcPath) {
+		if t.Age == nil {
+			return nil, errors.New("no age identity for encrypted file")
+		}
+		data, err = t.Age.Decrypt(data)
+	}
+	if err == nil && isTemplate(srcPath) {
+		data, err = renderTemplate(srcPath, data, t.Template)
+	}
+	return data, err
+}
+
+// Check if content of [srcPath] is not deployed as is, template or encrypted
+func isTransformed(srcPath string) bool {
+	return isTemplate(srcPath) || isEncrypted(srcPath)
 }
 
-// Get list of directory and list of file, while excluding
-//   - files with name containing substring in [t.FileSkip]
-//   - directories with name containing substring in [t.DirSkip]
-func (t *TypeDotfile) getDirFile(dir string) (dirs, files *[]string) {
+// Get list of directory and list of file, relative to [dir], while excluding
+//   - files and directories matching t.DirSkip, t.FileSkip and [FILE_IGNORE], see [TypeDotfileProperty.SkipMatch]
+//   - t.DirScripts in root of [dir]
+//   - alternates not best matching current machine, see [altSelect]
+func (t *TypeDotfile) getDirFile(dir string) (dirs, files *[]string, err error) {
 	var (
-		tmpDirs  []string
-		tmpFiles []string
+		root      string // walk is on real path of [dir]
+		rules     skipRules
+		substring = t.SkipMatch == SKIP_MATCH_SUBSTRING
+		tmpDirs   []string
+		tmpFiles  []string
 	)
-	symwalk.Walk(dir, func(p string, info os.FileInfo, err error) error {
+	if root, err = filepath.EvalSymlinks(dir); err == nil {
+		rules, err = readIgnore(dir)
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+	if !substring {
+		var dirSkip, fileSkip skipRules
+		if t.DirSkip != nil {
+			dirSkip = parseSkip(*t.DirSkip, true, false)
+		}
+		if t.FileSkip != nil {
+			fileSkip = parseSkip(*t.FileSkip, false, true)
+		}
+		rules = append(append(dirSkip, fileSkip...), rules...)
+	}
+	err = symwalk.Walk(root, func(p string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if p, err = filepath.Rel(root, p); err != nil || p == "." {
+			return err
+		}
 		if info.IsDir() {
-			if p != "." && !str.ArrayContainsSubString(t.DirSkip, "/"+p+"/", false) {
+			if p == t.DirScripts || rules.match(p, true) {
+				return filepath.SkipDir
+			}
+			if !(substring && str.ArrayContainsSubString(t.DirSkip, "/"+p+"/", false)) {
 				tmpDirs = append(tmpDirs, p)
 			}
 		} else {
-			if !str.ArrayContains(t.FileSkip, path.Base(p), false) && !str.ArrayContainsSubString(t.DirSkip, "/"+p, false) {
+			if p == FILE_IGNORE || rules.match(p, false) || rules.match(trimAlt(p), false) {
+				return nil
+			}
+			if !(substring && (str.ArrayContains(t.FileSkip, path.Base(p), false) ||
+				str.ArrayContains(t.FileSkip, path.Base(trimAlt(p)), false) ||
+				str.ArrayContainsSubString(t.DirSkip, "/"+p, false))) {
 				tmpFiles = append(tmpFiles, p)
 			}
 		}
 		return nil
 	})
-	return &tmpDirs, &tmpFiles
+	tmpFiles = altSelect(tmpFiles)
+	return &tmpDirs, &tmpFiles, err
 }
 
 // Create dotted/hidden directory
-func dirCreateHidden(dir, dirBase string) (e error) {
+//   - [backup] = record created directory, nil: no record
+func dirCreateHidden(dir, dirBase string, backup *TypeBackup) (e error) {
 	var prefix = "DirCreate"
 	if !(dir == "." || dir == "") {
 		dirDest := path.Join(dirBase, hiddenPath(dir))
 		if !file.IsDir(dirDest) {
-			if e = os.MkdirAll(dirDest, os.ModePerm); e == nil {
+			if backup != nil {
+				e = backup.SaveDir(dirDest)
+			}
+			if e == nil {
+				e = os.MkdirAll(dirDest, os.ModePerm)
+			}
+			if e == nil {
 				ezlog.Debug().N(prefix).N("created").M(dirDest).Out()
 			} else {
 				ezlog.Err().N(prefix).N("ERR").M(e).Out()
@@ -230,6 +461,26 @@ func dirCreateHidden(dir, dirBase string) (e error) {
 	return e
 }
 
+// Check existing link at [desPath], with content [target], against [srcPath]
+func linkState(target, srcPath, desPath string) LinkState {
+	if !filepath.IsAbs(target) {
+		target = filepath.Join(filepath.Dir(desPath), target)
+	}
+	if filepath.Clean(target) == srcPath {
+		return LINK_OK
+	}
+	if _, e := os.Stat(desPath); e != nil {
+		return LINK_DANGLING
+	}
+	return LINK_WRONG
+}
+
+// Check if [p] is a symlink
+func isSymlink(p string) bool {
+	info, e := os.Lstat(p)
+	return e == nil && info.Mode()&os.ModeSymlink != 0
+}
+
 // Add "."" in front of path if there is none
 func hiddenPath(p string) string {
 	if strings.HasPrefix(p, ".") {
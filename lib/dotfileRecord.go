@@ -1,10 +1,15 @@
 package lib
 
 import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"os"
+	"strconv"
 	"strings"
 	"text/tabwriter"
+	"time"
 
 	"github.com/J-Siu/go-helper/v2/ezlog"
 	"github.com/J-Siu/go-helper/v2/strany"
@@ -12,17 +17,184 @@ import (
 
 // Record struct to store processed dotfile information
 type TypeDotfileRecord struct {
+	BlockLabel   string       `json:"BlockLabel,omitempty"` // APPEND: label of block in destination
+	Data         []byte       `json:"-"`                    // COPY/APPEND: destination content after processing, only with KeepData
+	DataSize     int64        `json:"-"`                    // COPY/APPEND: size of destination content after processing
 	DesInfo      *os.FileInfo `json:"DesInfo"`
+	DesLink      string       `json:"DesLink"` // LINK: existing link target in destination
 	DesPath      string       `json:"DesPath"`
 	FileProcMode FileProcMode `json:"FileProcMode"`
+	LinkState    LinkState    `json:"LinkState"` // LINK: destination state before processing
+	Save         bool         `json:"Save"`      // true: saved, false: dry run
 	SrcInfo      *os.FileInfo `json:"SrcInfo"`
+	SrcMode      FileProcMode `json:"SrcMode"` // mode of source directory: COPY / APPEND / LINK
 	SrcPath      string       `json:"SrcPath"`
+	Variant      string       `json:"Variant"` // alternate conditions of chosen source, eg. os.linux
 }
 
 type TypeDotfileRecords []*TypeDotfileRecord
 
-func (t *TypeDotfileRecords) Output(noInfo, quiet, verbose, save bool) {
+// Output formats
+const (
+	OUTPUT_CSV    = "csv"
+	OUTPUT_JSON   = "json"   // array
+	OUTPUT_NDJSON = "ndjson" // one record per line
+	OUTPUT_TABLE  = "table"
+)
+
+// Check [format] is one of OUTPUT_*, before any change is made
+func OutputCheck(format string) (err error) {
+	switch format {
+	case OUTPUT_CSV, OUTPUT_JSON, OUTPUT_NDJSON, OUTPUT_TABLE:
+	default:
+		err = errors.New("invalid output format: " + format)
+	}
+	return err
+}
+
+// Record in machine readable output, with file info flatten
+type TypeDotfileRecordOutput struct {
+	DataSize     *int64       `json:"DataSize,omitempty"` // COPY/APPEND: size of destination content after processing, rendered for template
+	DesLink      string       `json:"DesLink,omitempty"`
+	DesModTime   *time.Time   `json:"DesModTime,omitempty"`
+	DesPath      string       `json:"DesPath"`
+	DesPerm      string       `json:"DesPerm,omitempty"` // eg. -rw-r--r--
+	DesSize      *int64       `json:"DesSize,omitempty"`
+	FileProcMode FileProcMode `json:"FileProcMode"`
+	LinkState    string       `json:"LinkState,omitempty"`
+	Save         bool         `json:"Save"`
+	SrcMode      FileProcMode `json:"SrcMode"`
+	SrcModTime   *time.Time   `json:"SrcModTime,omitempty"`
+	SrcPath      string       `json:"SrcPath"`
+	SrcPerm      string       `json:"SrcPerm,omitempty"`
+	SrcSize      *int64       `json:"SrcSize,omitempty"`
+	Variant      string       `json:"Variant,omitempty"`
+}
+
+// CSV columns, same as JSON keys of [TypeDotfileRecordOutput]
+var recordCSVHeader = []string{
+	"Save", "FileProcMode", "SrcMode", "LinkState",
+	"SrcPath", "SrcPerm", "SrcSize", "SrcModTime",
+	"DesPath", "DesPerm", "DesSize", "DesModTime",
+	"DesLink", "Variant", "DataSize",
+}
+
+// Record in machine readable output
+func (t *TypeDotfileRecord) ToOutput() *TypeDotfileRecordOutput {
+	out := TypeDotfileRecordOutput{
+		DesLink:      t.DesLink,
+		DesPath:      t.DesPath,
+		FileProcMode: t.FileProcMode,
+		Save:         t.Save,
+		SrcMode:      t.SrcMode,
+		SrcPath:      t.SrcPath,
+		Variant:      t.Variant,
+	}
+	if t.LinkState != LINK_NONE {
+		out.LinkState = t.LinkState.String()
+	}
+	if t.DesInfo != nil && *t.DesInfo != nil {
+		out.DesModTime, out.DesPerm, out.DesSize = fileInfoOutput(*t.DesInfo)
+	}
+	if t.SrcInfo != nil && *t.SrcInfo != nil {
+		out.SrcModTime, out.SrcPerm, out.SrcSize = fileInfoOutput(*t.SrcInfo)
+	}
+	if t.FileProcMode == APPEND || t.FileProcMode == COPY {
+		size := t.DataSize
+		out.DataSize = &size
+	}
+	return &out
+}
+
+// Machine readable output, as os.FileInfo marshals to {}
+func (t *TypeDotfileRecord) MarshalJSON() ([]byte, error) {
+	return json.Marshal(t.ToOutput())
+}
+
+func fileInfoOutput(info os.FileInfo) (modTime *time.Time, perm string, size *int64) {
+	m := info.ModTime()
+	s := info.Size()
+	return &m, info.Mode().String(), &s
+}
+
+// Output records in [format], see OUTPUT_*
+//   - [noInfo] = table: file path only
+//   - [quiet], [verbose] = which records, see [TypeDotfileRecords.filter]
+//   - [save] = table: dry run prefix
+func (t *TypeDotfileRecords) Output(format string, noInfo, quiet, verbose, save bool) (err error) {
+	records := t.filter(quiet, verbose)
+	switch format {
+	case OUTPUT_CSV:
+		w := csv.NewWriter(os.Stdout)
+		w.Write(recordCSVHeader)
+		for _, r := range records {
+			w.Write(r.ToOutput().csv())
+		}
+		w.Flush()
+		err = w.Error()
+	case OUTPUT_JSON:
+		var data []byte
+		if records == nil {
+			records = TypeDotfileRecords{} // "[]" instead of "null"
+		}
+		if data, err = json.MarshalIndent(records, "", "  "); err == nil {
+			fmt.Println(string(data))
+		}
+	case OUTPUT_NDJSON:
+		enc := json.NewEncoder(os.Stdout)
+		for _, r := range records {
+			if err = enc.Encode(r); err != nil {
+				break
+			}
+		}
+	case OUTPUT_TABLE:
+		t.outputTable(noInfo, quiet, verbose, save)
+	default:
+		err = OutputCheck(format)
+	}
+	return err
+}
+
+// Records to output
+//   - debug or [verbose]: all
+//   - [quiet]: none
+//   - else: not SKIP
+func (t *TypeDotfileRecords) filter(quiet, verbose bool) (records TypeDotfileRecords) {
+	for _, r := range *t {
+		if ezlog.GetLogLevel() >= ezlog.DEBUG ||
+			verbose ||
+			!quiet && r.FileProcMode != SKIP {
+			records = append(records, r)
+		}
+	}
+	return records
+}
+
+// Row of [recordCSVHeader]
+func (t *TypeDotfileRecordOutput) csv() []string {
+	timeStr := func(p *time.Time) string {
+		if p == nil {
+			return ""
+		}
+		return p.Format(time.RFC3339Nano)
+	}
+	sizeStr := func(p *int64) string {
+		if p == nil {
+			return ""
+		}
+		return strconv.FormatInt(*p, 10)
+	}
+	return []string{
+		strconv.FormatBool(t.Save), t.FileProcMode.String(), t.SrcMode.String(), t.LinkState,
+		t.SrcPath, t.SrcPerm, sizeStr(t.SrcSize), timeStr(t.SrcModTime),
+		t.DesPath, t.DesPerm, sizeStr(t.DesSize), timeStr(t.DesModTime),
+		t.DesLink, t.Variant, sizeStr(t.DataSize),
+	}
+}
+
+func (t *TypeDotfileRecords) outputTable(noInfo, quiet, verbose, save bool) {
 	const (
+		STR_NO_MODE     = "----------"
 		STR_NO_MODTIME  = "---------- --:--:--"
 		STR_TIME_FORMAT = "2006-01-02 15:04:05"
 	)
@@ -36,6 +208,9 @@ func (t *TypeDotfileRecords) Output(noInfo, quiet, verbose, save bool) {
 		var (
 			desModTimeStr string = STR_NO_MODTIME
 			desSize       int64
+			srcModeStr    string = STR_NO_MODE
+			srcModTimeStr string = STR_NO_MODTIME
+			srcSize       int64
 		)
 		// populate duplicate copy list
 		if r.FileProcMode == COPY {
@@ -56,7 +231,7 @@ func (t *TypeDotfileRecords) Output(noInfo, quiet, verbose, save bool) {
 			}
 			if noInfo { // file path only
 				recordStrArr = append(recordStrArr,
-					r.FileProcMode.String(),
+					r.modeStr(),
 					r.SrcPath,
 					"->",
 					r.DesPath,
@@ -66,11 +241,21 @@ func (t *TypeDotfileRecords) Output(noInfo, quiet, verbose, save bool) {
 					desModTimeStr = (*r.DesInfo).ModTime().Local().Format(STR_TIME_FORMAT)
 					desSize = (*r.DesInfo).Size()
 				}
+				// source may not exist, eg. prune
+				if r.SrcInfo != nil {
+					srcModeStr = (*r.SrcInfo).Mode().String()
+					srcModTimeStr = (*r.SrcInfo).ModTime().Local().Format(STR_TIME_FORMAT)
+					srcSize = (*r.SrcInfo).Size()
+				}
+				// size after processing, not of template or encrypted source
+				if (r.FileProcMode == APPEND || r.FileProcMode == COPY) && isTransformed(r.SrcPath) {
+					srcSize = r.DataSize
+				}
 				recordStrArr = append(recordStrArr,
-					r.FileProcMode.String(),
-					(*r.SrcInfo).Mode().String(),
-					strany.Any((*r.SrcInfo).Size()),
-					(*r.SrcInfo).ModTime().Local().Format(STR_TIME_FORMAT),
+					r.modeStr(),
+					srcModeStr,
+					strany.Any(srcSize),
+					srcModTimeStr,
 					r.SrcPath,
 					"->",
 					strany.Any(desSize),
@@ -89,6 +274,34 @@ func (t *TypeDotfileRecords) Output(noInfo, quiet, verbose, save bool) {
 	}
 }
 
+// true: any record is not SKIP
+func (t *TypeDotfileRecords) Changed() bool {
+	for _, r := range *t {
+		if r.FileProcMode != SKIP {
+			return true
+		}
+	}
+	return false
+}
+
+// Mode string for output, with link state on link repair
+func (t *TypeDotfileRecord) modeStr() string {
+	if t.FileProcMode == LINK && t.LinkState != LINK_NEW {
+		return t.FileProcMode.String() + "(" + t.LinkState.String() + ")"
+	}
+	return t.FileProcMode.String()
+}
+
+// Mode of destination after processing, decrypted file without group and other permission
+func (t *TypeDotfileRecord) deployMode() (mode os.FileMode) {
+	if t.SrcInfo != nil && *t.SrcInfo != nil {
+		if mode = (*t.SrcInfo).Mode(); t.FileProcMode != LINK && isEncrypted(t.SrcPath) {
+			mode &^= AGE_PERM_MASK
+		}
+	}
+	return mode
+}
+
 func outputDupList(dupList map[string][]string) {
 	var (
 		headerPrinted bool
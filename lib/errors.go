@@ -0,0 +1,73 @@
+/*
+Copyright © 2025 John, Sing Dao, Siu <john.sd.siu@gmail.com>
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+
+package lib
+
+import (
+	"errors"
+	"strings"
+)
+
+// Errors returned by the library, to be checked with errors.Is
+var (
+	ErrConfInvalid   = errors.New("config invalid")
+	ErrConfNotFound  = errors.New("config not found")
+	ErrDestMissing   = errors.New("destination directory missing")
+	ErrFileFailed    = errors.New("file failed") // a file or directory in destination not processed
+	ErrSrcUnreadable = errors.New("source unreadable")
+)
+
+// Problems found by TypeConf.Validate(), is [ErrConfInvalid]
+type TypeConfProblems []*TypeConfProblem
+
+func (t TypeConfProblems) Error() string {
+	var lines []string
+	for _, p := range t {
+		lines = append(lines, p.Error())
+	}
+	return strings.Join(lines, "\n")
+}
+
+func (t TypeConfProblems) Is(target error) bool {
+	return target == ErrConfInvalid
+}
+
+// Error of a path, matching [TypeError.Sentinel] and [TypeError.Err] with errors.Is
+type TypeError struct {
+	Err      error // cause, can be nil
+	Path     string
+	Sentinel error // one of Err* above
+}
+
+func (t *TypeError) Error() string {
+	if t.Err == nil {
+		return t.Sentinel.Error() + ": " + t.Path
+	}
+	return t.Sentinel.Error() + ": " + t.Path + ": " + t.Err.Error()
+}
+
+func (t *TypeError) Unwrap() []error {
+	if t.Err == nil {
+		return []error{t.Sentinel}
+	}
+	return []error{t.Sentinel, t.Err}
+}
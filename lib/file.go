@@ -0,0 +1,86 @@
+/*
+Copyright © 2025 John, Sing Dao, Siu <john.sd.siu@gmail.com>
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+
+package lib
+
+import (
+	"errors"
+	"os"
+	"path"
+	"path/filepath"
+	"time"
+)
+
+// Symlink values, how COPY/APPEND write a destination which is a symlink
+const (
+	SYMLINK_FOLLOW  = "follow"  // default, write to link target
+	SYMLINK_REPLACE = "replace" // replace link with a regular file
+)
+
+// Write [data] to [filePath] through a temporary file in the same directory
+// and rename, so [filePath] is either old or new content, never partial.
+func writeAtomic(filePath string, data []byte, perm os.FileMode) (err error) {
+	return writeAtomicTime(filePath, data, perm, time.Time{})
+}
+
+// Same as [writeAtomic], also set modification time to [modTime] before rename,
+// zero [modTime]: current time.
+//
+// A symlink at [filePath] is replaced, not followed.
+func writeAtomicTime(filePath string, data []byte, perm os.FileMode, modTime time.Time) (err error) {
+	var f *os.File
+	if f, err = os.CreateTemp(path.Dir(filePath), "."+path.Base(filePath)+".*"); err != nil {
+		return err
+	}
+	tmp := f.Name()
+	if _, err = f.Write(data); err == nil {
+		err = f.Sync()
+	}
+	if e := f.Close(); err == nil {
+		err = e
+	}
+	if err == nil {
+		err = os.Chmod(tmp, perm)
+	}
+	if err == nil && !modTime.IsZero() {
+		err = os.Chtimes(tmp, modTime, modTime)
+	}
+	if err == nil {
+		err = os.Rename(tmp, filePath)
+	}
+	if err != nil {
+		os.Remove(tmp)
+	}
+	return err
+}
+
+// Path [desPath] resolves to if it is a symlink, else [desPath]. Dangling symlink is an error.
+func followLink(desPath string) (string, error) {
+	if info, e := os.Lstat(desPath); e != nil || info.Mode()&os.ModeSymlink == 0 {
+		return desPath, nil
+	}
+	p, err := filepath.EvalSymlinks(desPath)
+	if err != nil {
+		err = errors.New("dangling symlink: " + desPath)
+	}
+	return p, err
+}
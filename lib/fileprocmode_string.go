@@ -11,12 +11,14 @@ func _() {
 	_ = x[APPEND-0]
 	_ = x[CHMOD-1]
 	_ = x[COPY-2]
-	_ = x[SKIP-3]
+	_ = x[LINK-3]
+	_ = x[REMOVE-4]
+	_ = x[SKIP-5]
 }
 
-const _FileProcMode_name = "APPENDCHMODCOPYSKIP"
+const _FileProcMode_name = "APPENDCHMODCOPYLINKREMOVESKIP"
 
-var _FileProcMode_index = [...]uint8{0, 6, 11, 15, 19}
+var _FileProcMode_index = [...]uint8{0, 6, 11, 15, 19, 25, 29}
 
 func (i FileProcMode) String() string {
 	idx := int(i) - 0
@@ -25,11 +25,47 @@ package lib
 // Holding all flags from command line
 type TypeFlag struct {
 	Debug   bool // Enable debug output
+	Jobs    int  // Files processed in parallel, 0: number of CPU
 	Trace   bool // Enable trace output
 	Verbose bool
 }
+type TypeFlagAdopt struct {
+	AllModified bool
+	NoInfo      bool
+	Output      string // table, json, ndjson, csv
+	Save        bool
+}
+type TypeFlagBackup struct {
+	NoInfo bool
+	Output string // table, json, ndjson, csv
+	Save   bool
+}
+type TypeFlagDecrypt struct {
+	Output string // output file, "": stdout
+}
+type TypeFlagDiff struct {
+	Stat bool
+}
+type TypeFlagEncrypt struct {
+	Output string // output file, "": input file with .age added
+}
+type TypeFlagPrune struct {
+	NoInfo bool
+	Output string // table, json, ndjson, csv
+	Save   bool
+}
+type TypeFlagRollback struct {
+	Force  bool // rollback even if rolled back, or newer runs changed same paths
+	NoInfo bool
+	Output string // table, json, ndjson, csv
+	Save   bool
+}
+type TypeFlagStatus struct {
+	Output string // table, json
+}
 type TypeFlagUpdate struct {
 	NoInfo bool
-	Quiet  bool // Show non-skip only
+	Output string // table, json, ndjson, csv
+	Quiet  bool   // Show non-skip only
 	Save   bool
 }
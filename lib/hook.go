@@ -0,0 +1,176 @@
+/*
+Copyright © 2025 John, Sing Dao, Siu <john.sd.siu@gmail.com>
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+
+package lib
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/bmatcuk/doublestar/v4"
+)
+
+// Hook names, also value of [ENV_HOOK]
+const (
+	HOOK_PATH        = "Path"
+	HOOK_POST_UPDATE = "PostUpdate"
+	HOOK_PRE_UPDATE  = "PreUpdate"
+)
+
+// Default hook timeout in seconds
+const HOOK_TIMEOUT = 60
+
+// Env vars of hook command
+const (
+	ENV_HOOK     = "GO_DOTFILE_HOOK"     // hook name
+	ENV_CHANGED  = "GO_DOTFILE_CHANGED"  // changed destination paths, one per line
+	ENV_DIR_DEST = "GO_DOTFILE_DIR_DEST" // DirDest
+	ENV_RUN_ID   = "GO_DOTFILE_RUN_ID"   // backup run ID
+)
+
+// Command run by saved update
+type TypeHook struct {
+	Command string   `json:"Command"`           // run with sh -c, in DirDest
+	Match   []string `json:"Match,omitempty"`   // Path: glob of destination, relative to DirDest or absolute
+	Timeout int      `json:"Timeout,omitempty"` // seconds, 0: Hooks.Timeout
+}
+
+// Hooks in configuration, only run by saved update
+type TypeHooks struct {
+	Path       []*TypeHook `json:"Path,omitempty"`       // after update, if a changed destination matches
+	PostUpdate []*TypeHook `json:"PostUpdate,omitempty"` // after update
+	PreUpdate  []*TypeHook `json:"PreUpdate,omitempty"`  // before update, a failure stops the update
+	Timeout    int         `json:"Timeout,omitempty"`    // seconds, 0: [HOOK_TIMEOUT]
+}
+
+// Hooks of [name], see HOOK_*
+func (t *TypeHooks) Hooks(name string) []*TypeHook {
+	return map[string][]*TypeHook{
+		HOOK_PATH:        t.Path,
+		HOOK_POST_UPDATE: t.PostUpdate,
+		HOOK_PRE_UPDATE:  t.PreUpdate,
+	}[name]
+}
+
+// Run hooks of [name] with changed ones of [records], in order of configuration.
+//   - [HOOK_PATH]: only hooks matching a changed destination, with matched records
+//   - [HOOK_PRE_UPDATE]: stop at first failure
+//
+// Changed records are passed in JSON on stdin, see [TypeDotfileRecordOutput], and in [ENV_CHANGED].
+// Output of hook goes to stderr, so stdout is kept for records.
+func (t *TypeHooks) Run(name string, records TypeDotfileRecords, dirDest, runID string) (err error) {
+	var changed TypeDotfileRecords
+	for _, r := range records {
+		if r.FileProcMode != SKIP {
+			changed = append(changed, r)
+		}
+	}
+	for _, hook := range t.Hooks(name) {
+		hookRecords := changed
+		if name == HOOK_PATH {
+			if hookRecords = hook.match(changed, dirDest); hookRecords == nil {
+				continue
+			}
+		}
+		timeout := t.Timeout
+		if hook.Timeout > 0 {
+			timeout = hook.Timeout
+		}
+		if e := hook.run(name, hookRecords, dirDest, runID, timeout); e != nil {
+			err = errors.Join(err, errors.New(name+": "+hook.Command+": "+e.Error()))
+			if name == HOOK_PRE_UPDATE {
+				break
+			}
+		}
+	}
+	return err
+}
+
+// Records with destination matching t.Match
+func (t *TypeHook) match(records TypeDotfileRecords, dirDest string) (matched TypeDotfileRecords) {
+	for _, r := range records {
+		rel, _ := filepath.Rel(dirDest, r.DesPath)
+		for _, pattern := range t.Match {
+			p := rel
+			if filepath.IsAbs(pattern) {
+				p = r.DesPath
+			}
+			if ok, _ := doublestar.Match(pattern, p); ok {
+				matched = append(matched, r)
+				break
+			}
+		}
+	}
+	return matched
+}
+
+// Run t.Command, killing its process group after [timeout] seconds, <= 0: [HOOK_TIMEOUT]
+func (t *TypeHook) run(name string, records TypeDotfileRecords, dirDest, runID string, timeout int) (err error) {
+	var (
+		data     []byte
+		desPaths []string
+	)
+	if records == nil {
+		records = TypeDotfileRecords{} // "[]" instead of "null"
+	}
+	if data, err = json.Marshal(records); err != nil {
+		return err
+	}
+	for _, r := range records {
+		desPaths = append(desPaths, r.DesPath)
+	}
+	if timeout <= 0 {
+		timeout = HOOK_TIMEOUT
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), time.Duration(timeout)*time.Second)
+	defer cancel()
+
+	cmd := exec.CommandContext(ctx, "sh", "-c", t.Command)
+	cmd.Dir = dirDest
+	cmd.Env = append(os.Environ(),
+		ENV_HOOK+"="+name,
+		ENV_CHANGED+"="+strings.Join(desPaths, "\n"),
+		ENV_DIR_DEST+"="+dirDest,
+		ENV_RUN_ID+"="+runID,
+	)
+	cmd.Stdin = bytes.NewReader(data)
+	cmd.Stdout = os.Stderr
+	cmd.Stderr = os.Stderr
+	// kill background children too
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setpgid: true}
+	cmd.Cancel = func() error { return syscall.Kill(-cmd.Process.Pid, syscall.SIGKILL) }
+	cmd.WaitDelay = time.Second
+
+	if err = cmd.Run(); err != nil && ctx.Err() == context.DeadlineExceeded {
+		err = errors.New("timeout after " + strconv.Itoa(timeout) + "s")
+	}
+	return err
+}
@@ -0,0 +1,177 @@
+/*
+Copyright © 2025 John, Sing Dao, Siu <john.sd.siu@gmail.com>
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+
+package lib
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/J-Siu/go-helper/v2/file"
+	"github.com/pelletier/go-toml/v2"
+	"github.com/spf13/viper"
+	"go.yaml.in/yaml/v3"
+)
+
+// Read [filePath] and its includes, merging into [merged], included files first,
+// so values of [filePath] win.
+//   - [stack] = files being read, to catch include loop
+//
+// Origin of each value is kept in t.Origin.
+func (t *TypeConf) readFile(filePath string, merged map[string]any, stack map[string]bool) (err error) {
+	if stack[filePath] {
+		return errors.New("include loop: " + filePath)
+	}
+	stack[filePath] = true
+	defer delete(stack, filePath)
+
+	v := viper.New()
+	v.SetConfigType(confType(filePath))
+	v.SetConfigFile(filePath)
+	if err = v.ReadInConfig(); err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			err = &TypeError{Err: err, Path: filePath, Sentinel: ErrConfNotFound}
+		}
+		return err
+	}
+	for _, pattern := range v.GetStringSlice("Include") {
+		var matches []string
+		// relative to including file
+		pattern = file.TildeEnvExpand(pattern)
+		if !path.IsAbs(pattern) {
+			pattern = path.Join(path.Dir(filePath), pattern)
+		}
+		if matches, err = filepath.Glob(pattern); err == nil && matches == nil && !strings.ContainsAny(pattern, "*?[") {
+			err = &TypeError{Path: pattern, Sentinel: ErrConfNotFound}
+		}
+		for _, m := range matches {
+			if err == nil {
+				err = t.readFile(m, merged, stack)
+			}
+		}
+		if err != nil {
+			return fmt.Errorf("%s: %w", filePath, err)
+		}
+	}
+	settings := v.AllSettings()
+	var all map[string]any
+	if all, err = confDecode(filePath); err != nil {
+		return fmt.Errorf("%s: %w", filePath, err)
+	}
+	t.files = append(t.files, &confFile{path: filePath, raw: all})
+	for _, name := range confRawKeys {
+		delete(settings, strings.ToLower(name))
+	}
+	for k, value := range confRaw(all) {
+		settings[k] = value
+	}
+	confMerge(merged, settings, "", filePath, t.Origin)
+	return nil
+}
+
+// Settings with map keys kept as is, not lowercased or split on "." by viper, eg. ".vimrc", "GitEmail"
+var confRawKeys = []string{"Comment", "Data"}
+
+// Config file read, with its content decoded without viper, keys as written
+type confFile struct {
+	path string
+	raw  map[string]any
+}
+
+// Content of [filePath], decoded without viper
+func confDecode(filePath string) (all map[string]any, err error) {
+	var data []byte
+	all = make(map[string]any)
+	if data, err = os.ReadFile(filePath); err == nil {
+		switch confType(filePath) {
+		case "toml":
+			err = toml.Unmarshal(data, &all)
+		case "yaml", "yml":
+			err = yaml.Unmarshal(data, &all)
+		default:
+			err = json.Unmarshal(data, &all)
+		}
+	}
+	return all, err
+}
+
+// Values of [confRawKeys] in [all], with lowercase top level key
+func confRaw(all map[string]any) (raw map[string]any) {
+	raw = make(map[string]any)
+	for k, v := range all {
+		for _, name := range confRawKeys {
+			if strings.EqualFold(k, name) {
+				raw[strings.ToLower(name)] = v
+			}
+		}
+	}
+	return raw
+}
+
+// Merge [src] of [filePath] into [dst]. Lists are concatenated, maps merged, scalars overridden.
+//   - [keyPath] = key path of [src], "" for top level
+//   - [origin] = key path -> file path
+func confMerge(dst, src map[string]any, keyPath, filePath string, origin map[string]string) {
+	for k, value := range src {
+		p := confKeyName(k)
+		if keyPath != "" {
+			p = keyPath + "." + k
+		}
+		switch v := value.(type) {
+		case map[string]any:
+			d, ok := dst[k].(map[string]any)
+			if !ok {
+				d = make(map[string]any)
+				dst[k] = d
+			}
+			confMerge(d, v, p, filePath, origin)
+		case []any:
+			d, _ := dst[k].([]any)
+			for i := range v {
+				origin[p+"["+strconv.Itoa(len(d)+i)+"]"] = filePath
+			}
+			dst[k] = append(d, v...)
+		default:
+			dst[k] = value
+			origin[p] = filePath
+		}
+	}
+}
+
+// Field name of top level [key], which is lowercase after reading, eg. dircp -> DirCP
+func confKeyName(key string) string {
+	confType := reflect.TypeOf(TypeConf{})
+	for i := 0; i < confType.NumField(); i++ {
+		if name := confType.Field(i).Name; strings.EqualFold(name, key) {
+			return name
+		}
+	}
+	return key
+}
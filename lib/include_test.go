@@ -0,0 +1,104 @@
+/*
+Copyright © 2025 John, Sing Dao, Siu <john.sd.siu@gmail.com>
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+
+package lib
+
+import (
+	"reflect"
+	"testing"
+)
+
+// Source of confMerge from one file
+type confMergeSrc struct {
+	file string
+	src  map[string]any
+}
+
+func TestConfMerge(t *testing.T) {
+	tests := []struct {
+		name       string
+		srcs       []confMergeSrc
+		want       map[string]any
+		wantOrigin map[string]string
+	}{
+		{
+			name: "lists concatenated",
+			srcs: []confMergeSrc{
+				{"a", map[string]any{"dircp": []any{"x", "y"}}},
+				{"b", map[string]any{"dircp": []any{"z"}}},
+			},
+			want:       map[string]any{"dircp": []any{"x", "y", "z"}},
+			wantOrigin: map[string]string{"DirCP[0]": "a", "DirCP[1]": "a", "DirCP[2]": "b"},
+		},
+		{
+			name: "scalars overridden",
+			srcs: []confMergeSrc{
+				{"a", map[string]any{"dirdest": "x", "compare": "hash"}},
+				{"b", map[string]any{"dirdest": "y"}},
+			},
+			want:       map[string]any{"dirdest": "y", "compare": "hash"},
+			wantOrigin: map[string]string{"DirDest": "b", "Compare": "a"},
+		},
+		{
+			name: "maps merged",
+			srcs: []confMergeSrc{
+				{"a", map[string]any{"profiles": map[string]any{"work": map[string]any{"dircp": []any{"x"}, "override": true}}}},
+				{"b", map[string]any{"profiles": map[string]any{"work": map[string]any{"dircp": []any{"y"}}, "home": map[string]any{"dirdest": "z"}}}},
+			},
+			want: map[string]any{"profiles": map[string]any{
+				"work": map[string]any{"dircp": []any{"x", "y"}, "override": true},
+				"home": map[string]any{"dirdest": "z"},
+			}},
+			wantOrigin: map[string]string{
+				"Profiles.work.dircp[0]": "a",
+				"Profiles.work.dircp[1]": "b",
+				"Profiles.work.override": "a",
+				"Profiles.home.dirdest":  "b",
+			},
+		},
+		{
+			name: "unknown key as is",
+			srcs: []confMergeSrc{
+				{"a", map[string]any{"dirxx": 1}},
+			},
+			want:       map[string]any{"dirxx": 1},
+			wantOrigin: map[string]string{"dirxx": "a"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var (
+				got    = make(map[string]any)
+				origin = make(map[string]string)
+			)
+			for _, s := range tt.srcs {
+				confMerge(got, s.src, "", s.file, origin)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("confMerge() = %v, want %v", got, tt.want)
+			}
+			if !reflect.DeepEqual(origin, tt.wantOrigin) {
+				t.Errorf("confMerge() origin = %v, want %v", origin, tt.wantOrigin)
+			}
+		})
+	}
+}
@@ -0,0 +1,319 @@
+/*
+Copyright © 2025 John, Sing Dao, Siu <john.sd.siu@gmail.com>
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+
+package lib
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"io"
+	"os"
+	"path"
+	"strconv"
+	"time"
+
+	"github.com/J-Siu/go-helper/v2/basestruct"
+	"github.com/J-Siu/go-helper/v2/ezlog"
+	"github.com/J-Siu/go-helper/v2/file"
+)
+
+const (
+	FILE_MANIFEST    = "manifest.json"
+	MANIFEST_VERSION = 1 // bump on incompatible change of TypeManifestEntry
+)
+
+// Deployed file information
+//   - Des*: destination file after deployment, following symlink
+//   - Src*: source file at deployment
+type TypeManifestEntry struct {
+	BlockLabel string       `json:"BlockLabel,omitempty"` // APPEND: label of block, empty for earlier versions
+	DesHash    string       `json:"DesHash"`              // sha256 hex
+	DesModTime time.Time    `json:"DesModTime"`
+	DesPath    string       `json:"DesPath"`
+	DesSize    int64        `json:"DesSize"`
+	Deployed   time.Time    `json:"Deployed"` // last time destination was changed by go-dotfile
+	SrcHash    string       `json:"SrcHash"`  // sha256 hex
+	SrcModTime time.Time    `json:"SrcModTime"`
+	SrcMode    FileProcMode `json:"SrcMode"` // COPY / APPEND / LINK
+	SrcPath    string       `json:"SrcPath"`
+	SrcSize    int64        `json:"SrcSize"`
+}
+
+// Files deployed by previous saved updates, stored in [FILE_MANIFEST] under DirState
+type TypeManifest struct {
+	*basestruct.Base `json:"-"`
+	Entries          []*TypeManifestEntry `json:"Entries"`
+	FilePath         string               `json:"-"`
+	Version          int                  `json:"Version"`
+}
+
+func (t *TypeManifest) New(dirState string) *TypeManifest {
+	t.Base = new(basestruct.Base)
+	t.Initialized = true
+	t.MyType = "TypeManifest"
+	t.Entries = nil
+	t.FilePath = path.Join(dirState, FILE_MANIFEST)
+	t.Version = MANIFEST_VERSION
+	return t
+}
+
+// Read manifest file. A missing file is an empty manifest.
+func (t *TypeManifest) Read() *TypeManifest {
+	prefix := t.MyType + ".Read"
+	if !t.CheckErrInit(prefix) {
+		return t
+	}
+	var data *[]byte
+	if data, t.Err = file.ReadByte(t.FilePath); t.Err == nil {
+		t.Version = 0 // manifest before versioning has no "Version"
+		t.Err = json.Unmarshal(*data, t)
+	} else if os.IsNotExist(t.Err) {
+		t.Err = nil
+	}
+	if t.Err == nil && t.Version > MANIFEST_VERSION {
+		t.Err = errors.New(t.FilePath + ": unsupported version " + strconv.Itoa(t.Version))
+	}
+	ezlog.Debug().N(prefix).N(t.FilePath).M(len(t.Entries)).Out()
+	return t
+}
+
+// Write manifest file atomically, creating DirState if needed
+func (t *TypeManifest) Write() *TypeManifest {
+	prefix := t.MyType + ".Write"
+	if !t.CheckErrInit(prefix) {
+		return t
+	}
+	var data []byte
+	t.Version = MANIFEST_VERSION
+	if t.Err = os.MkdirAll(path.Dir(t.FilePath), os.ModePerm); t.Err == nil {
+		data, t.Err = json.MarshalIndent(t, "", "  ")
+	}
+	if t.Err == nil {
+		t.Err = writeAtomic(t.FilePath, data, 0600)
+	}
+	return t
+}
+
+// Add or replace entries of [records], which must be already saved.
+//
+// Hashes of unchanged (same size and modification time) files are reused, not re-read.
+func (t *TypeManifest) Update(records TypeDotfileRecords) *TypeManifest {
+	prefix := t.MyType + ".Update"
+	if !t.CheckErrInit(prefix) {
+		return t
+	}
+	var (
+		index = make(map[[2]string]int)
+		now   = time.Now()
+	)
+	for i, e := range t.Entries {
+		index[[2]string{e.DesPath, e.SrcPath}] = i
+	}
+	for _, r := range records {
+		var (
+			entry = TypeManifestEntry{
+				BlockLabel: r.BlockLabel,
+				DesPath:    r.DesPath,
+				Deployed:   now,
+				SrcMode:    r.SrcMode,
+				SrcPath:    r.SrcPath,
+			}
+			key     = [2]string{r.DesPath, r.SrcPath}
+			i, ok   = index[key]
+			old     = new(TypeManifestEntry)
+			srcInfo os.FileInfo
+			desInfo os.FileInfo
+		)
+		if ok {
+			old = t.Entries[i]
+			if r.FileProcMode == SKIP {
+				entry.Deployed = old.Deployed
+			}
+		}
+		if srcInfo, t.Err = os.Stat(r.SrcPath); t.Err == nil {
+			entry.SrcModTime = srcInfo.ModTime()
+			entry.SrcSize = srcInfo.Size()
+			entry.SrcHash, t.Err = cachedHash(r.SrcPath, srcInfo, old.SrcHash, old.SrcSize, old.SrcModTime)
+		}
+		if t.Err == nil {
+			if desInfo, t.Err = os.Stat(r.DesPath); t.Err == nil {
+				entry.DesModTime = desInfo.ModTime()
+				entry.DesSize = desInfo.Size()
+				entry.DesHash, t.Err = cachedHash(r.DesPath, desInfo, old.DesHash, old.DesSize, old.DesModTime)
+			}
+		}
+		if t.Err != nil {
+			break
+		}
+		if ok {
+			t.Entries[i] = &entry
+		} else {
+			index[key] = len(t.Entries)
+			t.Entries = append(t.Entries, &entry)
+		}
+	}
+	return t
+}
+
+// Remove all entries of [desPath]
+func (t *TypeManifest) Remove(desPath string) *TypeManifest {
+	var entries []*TypeManifestEntry
+	for _, e := range t.Entries {
+		if e.DesPath != desPath {
+			entries = append(entries, e)
+		}
+	}
+	t.Entries = entries
+	return t
+}
+
+// Remove [entry]
+func (t *TypeManifest) RemoveEntry(entry *TypeManifestEntry) *TypeManifest {
+	var entries []*TypeManifestEntry
+	for _, e := range t.Entries {
+		if e != entry {
+			entries = append(entries, e)
+		}
+	}
+	t.Entries = entries
+	return t
+}
+
+// Update destination size, modification time and hash of entries of [desPath],
+// after it is changed other than by update, eg. prune of an APPEND block
+func (t *TypeManifest) UpdateDes(desPath string) (err error) {
+	var (
+		hash string
+		info os.FileInfo
+	)
+	entries := t.Get(desPath)
+	if len(entries) == 0 {
+		return nil
+	}
+	if info, err = os.Stat(desPath); err == nil {
+		hash, err = fileHash(desPath)
+	}
+	if err == nil {
+		for _, e := range entries {
+			e.DesHash = hash
+			e.DesModTime = info.ModTime()
+			e.DesSize = info.Size()
+		}
+	}
+	return err
+}
+
+// --- Query
+
+// Entries of [desPath], more than one if appended from multiple sources
+func (t *TypeManifest) Get(desPath string) (entries []*TypeManifestEntry) {
+	for _, e := range t.Entries {
+		if e.DesPath == desPath {
+			entries = append(entries, e)
+		}
+	}
+	return entries
+}
+
+// Entry of [desPath] from [srcPath], nil if not found
+func (t *TypeManifest) Entry(desPath, srcPath string) *TypeManifestEntry {
+	for _, e := range t.Entries {
+		if e.DesPath == desPath && e.SrcPath == srcPath {
+			return e
+		}
+	}
+	return nil
+}
+
+// [desPath] is deployed by go-dotfile
+func (t *TypeManifest) Managed(desPath string) bool {
+	return len(t.Get(desPath)) > 0
+}
+
+// [desPath] is changed since deployment. Not managed -> false.
+func (t *TypeManifest) Modified(desPath string) (modified bool, err error) {
+	var (
+		entries = t.Get(desPath)
+		hash    string
+		info    os.FileInfo
+	)
+	if len(entries) == 0 {
+		return false, nil
+	}
+	e := entries[0]
+	if info, err = os.Stat(desPath); err == nil {
+		hash, err = cachedHash(desPath, info, e.DesHash, e.DesSize, e.DesModTime)
+	}
+	if err == nil {
+		modified = hash != e.DesHash
+	} else if os.IsNotExist(err) {
+		err = nil
+		modified = true
+	}
+	return modified, err
+}
+
+// Entries with destination not in [records]. APPEND entries also with source,
+// without alternate conditions, not appended to destination in [records].
+func (t *TypeManifest) Stale(records TypeDotfileRecords) (entries []*TypeManifestEntry) {
+	current := make(map[string]bool)
+	for _, r := range records {
+		current[r.DesPath] = true
+		if r.SrcMode == APPEND {
+			current[r.DesPath+"\n"+trimAlt(r.SrcPath)] = true
+		}
+	}
+	for _, e := range t.Entries {
+		key := e.DesPath
+		if e.SrcMode == APPEND {
+			key += "\n" + trimAlt(e.SrcPath)
+		}
+		if !current[key] {
+			entries = append(entries, e)
+		}
+	}
+	return entries
+}
+
+// Return [hash] if [info] has same size and modification time, else hash of [filePath]
+func cachedHash(filePath string, info os.FileInfo, hash string, size int64, modTime time.Time) (string, error) {
+	if hash != "" && info.Size() == size && info.ModTime().Equal(modTime) {
+		return hash, nil
+	}
+	return fileHash(filePath)
+}
+
+// sha256 hex of [filePath] content
+func fileHash(filePath string) (hash string, err error) {
+	var f *os.File
+	h := sha256.New()
+	if f, err = os.Open(filePath); err == nil {
+		_, err = io.Copy(h, f)
+		f.Close()
+	}
+	if err == nil {
+		hash = hex.EncodeToString(h.Sum(nil))
+	}
+	return hash, err
+}
@@ -0,0 +1,107 @@
+/*
+Copyright © 2025 John, Sing Dao, Siu <john.sd.siu@gmail.com>
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+
+package lib
+
+import "errors"
+
+// Source directories of a mode
+type modeDirs struct {
+	mode FileProcMode
+	dirs []string
+}
+
+// Process all source directories of configuration: DirCP, DirAP, then DirLN
+//   - [save] = true: save, false: dry run
+//   - [jobs] = number of files processed in parallel, <= 0: number of CPU
+//   - [backup] = backup destination before overwrite, nil: no backup
+//
+// Records are in order of source directories and files, regardless of [jobs].
+// Errors are joined in one level, a source directory error does not stop the others:
+//   - source directories: [ErrSrcUnreadable]
+//   - individual files and directories: [ErrFileFailed], with destination path
+//
+// Records of failed files are not returned.
+func (t *TypeConf) Process(save bool, jobs int, backup *TypeBackup) (records TypeDotfileRecords, err error) {
+	return t.process(save, jobs, backup, false)
+}
+
+// Same as [TypeConf.Process] in dry run, with destination content after processing
+// kept in Data of COPY/APPEND records, for [TypeDotfileRecord.Diff]
+func (t *TypeConf) ProcessData(jobs int) (records TypeDotfileRecords, err error) {
+	return t.process(false, jobs, nil, true)
+}
+
+func (t *TypeConf) process(save bool, jobs int, backup *TypeBackup, keepData bool) (records TypeDotfileRecords, err error) {
+	var (
+		modeDirs = []modeDirs{
+			{COPY, t.DirCP},
+			{APPEND, t.DirAP},
+			{LINK, t.DirLN},
+		}
+		ageID    = new(TypeAge).New(t.Identity, t.Recipients)
+		errList  []error
+		manifest *TypeManifest
+		tasks    []*dotfileTask
+		template = new(TypeTemplateData).New(t.Data)
+	)
+	if t.Compare == COMPARE_HASH {
+		// cached hashes only, hash is computed if manifest cannot be read
+		if manifest = new(TypeManifest).New(t.DirState).Read(); manifest.Err != nil {
+			manifest = nil
+		}
+	}
+	for _, m := range modeDirs {
+		for _, dir := range m.dirs {
+			property := TypeDotfileProperty{
+				Age:        ageID,
+				Backup:     backup,
+				Comment:    &t.Comment,
+				Compare:    t.Compare,
+				DirDest:    &t.DirDest,
+				DirScripts: t.DirScripts,
+				DirSkip:    &t.DirSkip,
+				DirSrc:     &dir,
+				FileSkip:   &t.FileSkip,
+				KeepData:   keepData,
+				Manifest:   manifest,
+				Mode:       m.mode,
+				Save:       save,
+				SkipMatch:  t.SkipMatch,
+				Symlink:    t.Symlink,
+				Template:   template,
+			}
+			dfTasks, e := new(TypeDotfile).New(&property).prepare()
+			errList = append(errList, e...)
+			tasks = append(tasks, dfTasks...)
+		}
+	}
+	records, e := runTasks(tasks, jobs)
+	return records, errors.Join(append(errList, e...)...)
+}
+
+// All source directories, in order of processing: DirCP, DirAP, then DirLN
+func (t *TypeConf) SrcDirs() (dirs []string) {
+	dirs = append(dirs, t.DirCP...)
+	dirs = append(dirs, t.DirAP...)
+	return append(dirs, t.DirLN...)
+}
@@ -0,0 +1,96 @@
+/*
+Copyright © 2025 John, Sing Dao, Siu <john.sd.siu@gmail.com>
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+
+package lib
+
+import (
+	"errors"
+	"os"
+	"sort"
+	"strings"
+)
+
+// Env var selecting profiles, when --profile is not used
+const ENV_PROFILE = "GO_DOTFILE_PROFILE"
+
+// Named profile in configuration, adding to or overriding the top level settings
+type TypeProfile struct {
+	DirAP    []string `json:"DirAP,omitempty"`
+	DirCP    []string `json:"DirCP,omitempty"`
+	DirDest  string   `json:"DirDest,omitempty"` // override if set
+	DirLN    []string `json:"DirLN,omitempty"`
+	DirSkip  []string `json:"DirSkip,omitempty"`
+	FileSkip []string `json:"FileSkip,omitempty"`
+	Hosts    []string `json:"Hosts,omitempty"`    // selected automatically on these hostnames
+	Override bool     `json:"Override,omitempty"` // true: lists replace top level ones, false: added to them
+}
+
+// Names of profiles to apply, in order
+//   - t.Profile, comma separated, from --profile, [ENV_PROFILE] or config file
+//   - else all profiles with hostname in Hosts, sorted by name
+func (t *TypeConf) profileNames() (names []string, err error) {
+	if t.Profile != "" {
+		for _, name := range strings.Split(t.Profile, ",") {
+			// profile names are lowercase after reading config
+			name = strings.ToLower(strings.TrimSpace(name))
+			if _, ok := t.Profiles[name]; !ok {
+				return nil, errors.New("profile not found: " + name)
+			}
+			names = append(names, name)
+		}
+		return names, nil
+	}
+	hostname, _ := os.Hostname()
+	for name, profile := range t.Profiles {
+		for _, host := range profile.Hosts {
+			if strings.EqualFold(host, hostname) {
+				names = append(names, name)
+				break
+			}
+		}
+	}
+	sort.Strings(names)
+	return names, nil
+}
+
+// Apply selected profiles on top level settings. Should be called before expand().
+func (t *TypeConf) applyProfile() (err error) {
+	var names []string
+	if names, err = t.profileNames(); err != nil {
+		return err
+	}
+	for _, name := range names {
+		p := t.Profiles[name]
+		if p.DirDest != "" {
+			t.DirDest = p.DirDest
+		}
+		for _, name := range confListKeys {
+			if p.Override {
+				*confList(t, name) = *profileList(p, name)
+			} else {
+				*confList(t, name) = append(*confList(t, name), *profileList(p, name)...)
+			}
+		}
+	}
+	t.Profile = strings.Join(names, ",")
+	return nil
+}
@@ -0,0 +1,136 @@
+/*
+Copyright © 2025 John, Sing Dao, Siu <john.sd.siu@gmail.com>
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+
+package lib
+
+import (
+	"errors"
+	"os"
+)
+
+// Remove deployed files no longer in any source directory
+//   - [manifest] = deployment manifest, removed entries are dropped from it on save
+//   - [current] = records of all source directories, from a dry run
+//   - [save] = true: remove, false: dry run
+//   - [backup] = backup destination before change, nil: no backup
+//
+// Errors are joined, each an [ErrFileFailed] of its destination
+func (t *TypeConf) Prune(manifest *TypeManifest, current TypeDotfileRecords, save bool, backup *TypeBackup) (records TypeDotfileRecords, err error) {
+	var (
+		done    = make(map[string]bool) // multiple entries for same destination
+		errList []error
+	)
+	for _, entry := range manifest.Stale(current) {
+		// APPEND: each source has its own block
+		key := entry.DesPath
+		if entry.SrcMode == APPEND {
+			key += "\n" + trimAlt(entry.SrcPath)
+		}
+		if done[key] {
+			continue
+		}
+		done[key] = true
+		record, e := t.pruneFile(manifest, entry, save, backup)
+		if e == nil {
+			records = append(records, record)
+			if save {
+				manifest.RemoveEntry(entry)
+				// other blocks are still deployed
+				if entry.SrcMode == APPEND && record.FileProcMode == REMOVE {
+					e = manifest.UpdateDes(entry.DesPath)
+				}
+			}
+		}
+		if e != nil {
+			errList = append(errList, &TypeError{Err: e, Path: entry.DesPath, Sentinel: ErrFileFailed})
+		}
+	}
+	return records, errors.Join(errList...)
+}
+
+// Remove destination of [entry] if it is still what go-dotfile deployed
+//   - LINK: symlink to entry.SrcPath
+//   - COPY: regular file, not modified since deployed
+//   - APPEND: block of entry.SrcPath only, rest of file is kept
+//
+// Missing destination or block is a SKIP
+func (t *TypeConf) pruneFile(manifest *TypeManifest, entry *TypeManifestEntry, save bool, backup *TypeBackup) (record *TypeDotfileRecord, err error) {
+	var (
+		data     []byte
+		desInfo  os.FileInfo
+		modified bool
+	)
+	record = &TypeDotfileRecord{
+		DesPath:      entry.DesPath,
+		FileProcMode: REMOVE,
+		Save:         save,
+		SrcMode:      entry.SrcMode,
+		SrcPath:      entry.SrcPath,
+	}
+	if desInfo, err = os.Lstat(entry.DesPath); err == nil {
+		record.DesInfo = &desInfo
+		switch {
+		case entry.SrcMode == LINK:
+			if desInfo.Mode()&os.ModeSymlink == 0 {
+				err = errors.New("not a symlink")
+			} else if record.DesLink, err = os.Readlink(entry.DesPath); err == nil {
+				if linkState(record.DesLink, entry.SrcPath, entry.DesPath) != LINK_OK {
+					err = errors.New("symlink not pointing to " + entry.SrcPath)
+				}
+			}
+		case !desInfo.Mode().IsRegular():
+			err = errors.New("not a regular file")
+		case entry.SrcMode == APPEND:
+			var found bool
+			if data, err = os.ReadFile(entry.DesPath); err == nil {
+				open, close := commentOf(entry.DesPath, &t.Comment)
+				label := entry.BlockLabel
+				if label == "" {
+					label = blockLabelV1(entry.SrcPath)
+				}
+				if data, found = removeBlock(data, label, open, close); !found {
+					record.FileProcMode = SKIP
+				}
+			}
+		default:
+			if modified, err = manifest.Modified(entry.DesPath); err == nil && modified {
+				err = errors.New("modified since deployed, not removed")
+			}
+		}
+	} else if os.IsNotExist(err) {
+		err = nil
+		record.FileProcMode = SKIP
+	}
+	if err == nil && record.FileProcMode == REMOVE && save {
+		if backup != nil {
+			err = backup.Save(entry.DesPath)
+		}
+		if err == nil {
+			if entry.SrcMode == APPEND {
+				err = writeAtomic(entry.DesPath, data, desInfo.Mode().Perm())
+			} else {
+				err = os.Remove(entry.DesPath)
+			}
+		}
+	}
+	return record, err
+}
@@ -0,0 +1,186 @@
+/*
+Copyright © 2025 John, Sing Dao, Siu <john.sd.siu@gmail.com>
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+
+package lib
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"os/exec"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/J-Siu/go-helper/v2/basestruct"
+	"github.com/J-Siu/go-helper/v2/ezlog"
+	"github.com/J-Siu/go-helper/v2/file"
+)
+
+const (
+	DIR_SCRIPTS     = "scripts"       // default DirScripts, in root of source directory, not deployed
+	FILE_SCRIPTS    = "scripts.json"  // under DirState
+	SCRIPT_ONCE     = "run_once_"     // run once per machine
+	SCRIPT_ONCHANGE = "run_onchange_" // run again when content changes
+	SCRIPTS_VERSION = 1               // bump on incompatible change of TypeScript
+)
+
+// Script in DirScripts of a source directory
+type TypeScript struct {
+	Hash    string    `json:"Hash"` // sha256 hex
+	Key     string    `json:"Key"`  // source directory/DirScripts/name, without alternate conditions
+	Path    string    `json:"-"`    // file of best matching alternate
+	RunTime time.Time `json:"RunTime"`
+}
+
+// Scripts already run by saved updates, stored in [FILE_SCRIPTS] under DirState
+type TypeScripts struct {
+	*basestruct.Base `json:"-"`
+	FilePath         string                 `json:"-"`
+	Scripts          map[string]*TypeScript `json:"Scripts"` // Key -> last run
+	Version          int                    `json:"Version"`
+}
+
+func (t *TypeScripts) New(dirState string) *TypeScripts {
+	t.Base = new(basestruct.Base)
+	t.Initialized = true
+	t.MyType = "TypeScripts"
+	t.FilePath = path.Join(dirState, FILE_SCRIPTS)
+	t.Scripts = make(map[string]*TypeScript)
+	t.Version = SCRIPTS_VERSION
+	return t
+}
+
+// Read state file. A missing file is an empty state.
+func (t *TypeScripts) Read() *TypeScripts {
+	prefix := t.MyType + ".Read"
+	if !t.CheckErrInit(prefix) {
+		return t
+	}
+	var data *[]byte
+	if data, t.Err = file.ReadByte(t.FilePath); t.Err == nil {
+		t.Err = json.Unmarshal(*data, t)
+	} else if os.IsNotExist(t.Err) {
+		t.Err = nil
+	}
+	if t.Err == nil && t.Version > SCRIPTS_VERSION {
+		t.Err = errors.New(t.FilePath + ": unsupported version " + strconv.Itoa(t.Version))
+	}
+	if t.Scripts == nil {
+		t.Scripts = make(map[string]*TypeScript)
+	}
+	ezlog.Debug().N(prefix).N(t.FilePath).M(len(t.Scripts)).Out()
+	return t
+}
+
+// Write state file atomically, creating DirState if needed
+func (t *TypeScripts) Write() *TypeScripts {
+	prefix := t.MyType + ".Write"
+	if !t.CheckErrInit(prefix) {
+		return t
+	}
+	var data []byte
+	t.Version = SCRIPTS_VERSION
+	if t.Err = os.MkdirAll(path.Dir(t.FilePath), os.ModePerm); t.Err == nil {
+		data, t.Err = json.MarshalIndent(t, "", "  ")
+	}
+	if t.Err == nil {
+		t.Err = writeAtomic(t.FilePath, data, 0600)
+	}
+	return t
+}
+
+// Scripts in directory [dirScripts] of each of [srcDirs] to run, in order of [srcDirs] then name
+//   - [SCRIPT_ONCE]: not run before
+//   - [SCRIPT_ONCHANGE]: not run before, or content changed since last run
+//
+// Other files in [dirScripts] are ignored. Alternates are selected like other source files.
+func (t *TypeScripts) Pending(srcDirs []string, dirScripts string) (scripts []*TypeScript, err error) {
+	for _, dir := range srcDirs {
+		var (
+			dirScripts = path.Join(dir, dirScripts)
+			entries    []os.DirEntry
+			names      []string
+		)
+		if entries, err = os.ReadDir(dirScripts); os.IsNotExist(err) {
+			err = nil
+			continue
+		} else if err != nil {
+			break
+		}
+		for _, e := range entries {
+			name := trimAlt(e.Name())
+			if file.IsRegularFile(path.Join(dirScripts, e.Name())) &&
+				(strings.HasPrefix(name, SCRIPT_ONCE) || strings.HasPrefix(name, SCRIPT_ONCHANGE)) {
+				names = append(names, e.Name())
+			}
+		}
+		for _, name := range altSelect(names) {
+			script := TypeScript{
+				Key:  path.Join(dirScripts, trimAlt(name)),
+				Path: path.Join(dirScripts, name),
+			}
+			if script.Hash, err = fileHash(script.Path); err != nil {
+				return nil, err
+			}
+			last := t.Scripts[script.Key]
+			if last == nil || strings.HasPrefix(path.Base(script.Key), SCRIPT_ONCHANGE) && last.Hash != script.Hash {
+				scripts = append(scripts, &script)
+			}
+		}
+	}
+	return scripts, err
+}
+
+// Run [script] in [dirDest], and record it in state file on success.
+//
+// An executable script is run directly, else with sh. Output goes to stderr, so stdout is kept for records.
+func (t *TypeScripts) Run(script *TypeScript, dirDest string) *TypeScripts {
+	prefix := t.MyType + ".Run"
+	if !t.CheckErrInit(prefix) {
+		return t
+	}
+	var (
+		cmd  *exec.Cmd
+		info os.FileInfo
+	)
+	if info, t.Err = os.Stat(script.Path); t.Err != nil {
+		return t
+	}
+	if info.Mode()&0111 != 0 {
+		cmd = exec.Command(script.Path)
+	} else {
+		cmd = exec.Command("sh", script.Path)
+	}
+	cmd.Dir = dirDest
+	cmd.Env = append(os.Environ(), ENV_DIR_DEST+"="+dirDest)
+	cmd.Stdout = os.Stderr
+	cmd.Stderr = os.Stderr
+	ezlog.Debug().N(prefix).M(script.Path).Out()
+	if t.Err = cmd.Run(); t.Err == nil {
+		script.RunTime = time.Now()
+		t.Scripts[script.Key] = script
+		t.Write()
+	}
+	return t
+}
@@ -0,0 +1,117 @@
+/*
+Copyright © 2025 John, Sing Dao, Siu <john.sd.siu@gmail.com>
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+
+package lib
+
+import (
+	"bufio"
+	"os"
+	"path"
+	"strings"
+
+	"github.com/bmatcuk/doublestar/v4"
+)
+
+// Ignore file in root of source directory, with gitignore syntax. It is never deployed.
+const FILE_IGNORE = ".dotfileignore"
+
+// SkipMatch values
+const (
+	SKIP_MATCH_GLOB      = "glob"      // default, gitignore style glob
+	SKIP_MATCH_SUBSTRING = "substring" // legacy, DirSkip by substring, FileSkip by exact name
+)
+
+// Skip pattern, gitignore style
+type skipRule struct {
+	anchored bool // pattern with "/", relative to source root
+	dir      bool // match directory only
+	file     bool // match file only
+	negate   bool // "!": not skip
+	pattern  string
+}
+
+type skipRules []skipRule
+
+// Parse gitignore style [patterns]. Empty lines and lines starting with "#" are ignored.
+//   - [dir] = patterns match directory only, eg. DirSkip
+//   - [file] = patterns match file only, eg. FileSkip
+func parseSkip(patterns []string, dir, file bool) (rules skipRules) {
+	for _, p := range patterns {
+		rule := skipRule{dir: dir, file: file}
+		if p = strings.TrimSpace(p); p == "" || strings.HasPrefix(p, "#") {
+			continue
+		}
+		if strings.HasPrefix(p, "!") {
+			rule.negate = true
+			p = p[1:]
+		}
+		if strings.HasSuffix(p, "/") {
+			rule.dir = true
+			p = strings.TrimSuffix(p, "/")
+		}
+		rule.anchored = strings.Contains(p, "/")
+		rule.pattern = strings.TrimPrefix(p, "/")
+		rules = append(rules, rule)
+	}
+	return rules
+}
+
+// Read [FILE_IGNORE] of source directory [dir], missing file is not an error
+func readIgnore(dir string) (rules skipRules, err error) {
+	var f *os.File
+	if f, err = os.Open(path.Join(dir, FILE_IGNORE)); err != nil {
+		if os.IsNotExist(err) {
+			err = nil
+		}
+		return nil, err
+	}
+	defer f.Close()
+	var lines []string
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		lines = append(lines, scanner.Text())
+	}
+	return parseSkip(lines, false, false), scanner.Err()
+}
+
+// Check if [p], relative to source root, is skipped. Last matching rule wins.
+func (t skipRules) match(p string, isDir bool) (skip bool) {
+	for _, rule := range t {
+		if rule.dir && !isDir || rule.file && isDir {
+			continue
+		}
+		if rule.matchPath(p) {
+			skip = !rule.negate
+		}
+	}
+	return skip
+}
+
+// Anchored pattern match whole path, else base name at any level
+func (t skipRule) matchPath(p string) (ok bool) {
+	if t.anchored {
+		ok, _ = doublestar.Match(t.pattern, p)
+	} else {
+		ok, _ = doublestar.Match(t.pattern, path.Base(p))
+	}
+	return ok
+}
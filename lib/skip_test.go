@@ -0,0 +1,61 @@
+/*
+Copyright © 2025 John, Sing Dao, Siu <john.sd.siu@gmail.com>
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+
+package lib
+
+import "testing"
+
+func TestSkipRulesMatch(t *testing.T) {
+	tests := []struct {
+		name     string
+		patterns []string
+		dir      bool // patterns of DirSkip
+		file     bool // patterns of FileSkip
+		path     string
+		isDir    bool
+		want     bool
+	}{
+		{name: "base name at any level", patterns: []string{"*.swp"}, path: "a/b/.x.swp", want: true},
+		{name: "no match", patterns: []string{"*.swp"}, path: "a/b/x", want: false},
+		{name: "anchored matches whole path", patterns: []string{"a/*.md"}, path: "a/README.md", want: true},
+		{name: "anchored not at other level", patterns: []string{"a/*.md"}, path: "b/a/README.md", want: false},
+		{name: "leading slash anchored", patterns: []string{"/README.md"}, path: "README.md", want: true},
+		{name: "leading slash not in subdirectory", patterns: []string{"/README.md"}, path: "a/README.md", want: false},
+		{name: "doublestar", patterns: []string{"a/**/*.bak"}, path: "a/b/c/x.bak", want: true},
+		{name: "negation after match", patterns: []string{"*.md", "!keep.md"}, path: "keep.md", want: false},
+		{name: "negation before match", patterns: []string{"!keep.md", "*.md"}, path: "keep.md", want: true},
+		{name: "negation of other file", patterns: []string{"*.md", "!keep.md"}, path: "x.md", want: true},
+		{name: "trailing slash directory only", patterns: []string{"cache/"}, path: "cache", isDir: false, want: false},
+		{name: "trailing slash on directory", patterns: []string{"cache/"}, path: "a/cache", isDir: true, want: true},
+		{name: "DirSkip not file", patterns: []string{".git"}, dir: true, path: ".git", want: false},
+		{name: "DirSkip on directory", patterns: []string{".git"}, dir: true, path: ".git", isDir: true, want: true},
+		{name: "FileSkip not directory", patterns: []string{"x"}, file: true, path: "x", isDir: true, want: false},
+		{name: "comment and empty line", patterns: []string{"# x", "", "  "}, path: "# x", want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := parseSkip(tt.patterns, tt.dir, tt.file).match(tt.path, tt.isDir); got != tt.want {
+				t.Errorf("match(%q, %v) = %v, want %v", tt.path, tt.isDir, got, tt.want)
+			}
+		})
+	}
+}
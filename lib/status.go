@@ -0,0 +1,192 @@
+/*
+Copyright © 2025 John, Sing Dao, Siu <john.sd.siu@gmail.com>
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+
+package lib
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/J-Siu/go-helper/v2/ezlog"
+)
+
+// Status of destination file against source and manifest
+type FileStatus int8
+
+const (
+	STATUS_CONFLICT     FileStatus = iota // conflicting
+	STATUS_DES_MODIFIED                   // destination-modified
+	STATUS_MISSING                        // missing
+	STATUS_SRC_NEWER                      // source-newer
+	STATUS_SYNC                           // in-sync
+)
+
+func (s FileStatus) MarshalText() ([]byte, error) {
+	return []byte(s.String()), nil
+}
+
+func (s *FileStatus) UnmarshalText(text []byte) error {
+	for i := STATUS_CONFLICT; i <= STATUS_SYNC; i++ {
+		if i.String() == string(text) {
+			*s = i
+			return nil
+		}
+	}
+	return errors.New("invalid FileStatus: " + string(text))
+}
+
+type TypeStatus struct {
+	DesPath string       `json:"DesPath"`
+	SrcMode FileProcMode `json:"SrcMode"`
+	SrcPath string       `json:"SrcPath"`
+	Status  FileStatus   `json:"Status"`
+}
+
+type TypeStatuses []*TypeStatus
+
+// Status of [records] of [TypeConf.ProcessData], using [manifest] to tell which side changed since deployment
+//   - in-sync: destination is what update would deploy
+//   - missing: destination does not exist
+//   - source-newer: source changed, or permission differ
+//   - destination-modified: destination changed, or LINK destination replaced
+//   - conflicting: both changed, or destination not deployed by go-dotfile and differ
+func Status(records TypeDotfileRecords, manifest *TypeManifest) (statuses TypeStatuses, err error) {
+	for _, r := range records {
+		status := TypeStatus{
+			DesPath: r.DesPath,
+			SrcMode: r.SrcMode,
+			SrcPath: r.SrcPath,
+		}
+		switch {
+		case r.DesInfo == nil:
+			status.Status = STATUS_MISSING
+		case r.FileProcMode == SKIP:
+			status.Status = STATUS_SYNC
+		case r.FileProcMode == CHMOD:
+			status.Status = STATUS_SRC_NEWER
+		case r.FileProcMode == LINK:
+			status.Status = STATUS_DES_MODIFIED
+		default: // COPY, APPEND
+			status.Status, err = statusContent(r, manifest)
+		}
+		if err != nil {
+			break
+		}
+		statuses = append(statuses, &status)
+	}
+	return statuses, err
+}
+
+// Status of COPY/APPEND [record] with different destination.
+//
+// Compare with manifest, not [record] content, as a destination can be result of
+// multiple sources, eg. COPY then APPEND.
+func statusContent(record *TypeDotfileRecord, manifest *TypeManifest) (status FileStatus, err error) {
+	var (
+		desHash string
+		desInfo os.FileInfo
+		srcHash string
+		entry   = manifest.Entry(record.DesPath, record.SrcPath)
+	)
+	if entry == nil {
+		if record.Data == nil && record.DataSize > 0 {
+			return status, errors.New(record.DesPath + ": no content in record, not from ProcessData")
+		}
+		sum := sha256.Sum256(record.Data)
+		if desHash, err = fileHash(record.DesPath); err == nil && desHash == hex.EncodeToString(sum[:]) {
+			status = STATUS_SYNC
+		}
+		return status, err
+	}
+	if desInfo, err = os.Stat(record.DesPath); err == nil {
+		desHash, err = cachedHash(record.DesPath, desInfo, entry.DesHash, entry.DesSize, entry.DesModTime)
+	}
+	if err == nil {
+		srcHash, err = cachedHash(record.SrcPath, *record.SrcInfo, entry.SrcHash, entry.SrcSize, entry.SrcModTime)
+	}
+	desModified := desHash != entry.DesHash
+	srcModified := srcHash != entry.SrcHash
+	switch {
+	case desModified && srcModified:
+		status = STATUS_CONFLICT
+	case desModified:
+		status = STATUS_DES_MODIFIED
+	case srcModified:
+		status = STATUS_SRC_NEWER
+	default:
+		status = STATUS_SYNC
+	}
+	return status, err
+}
+
+// Any status not in-sync
+func (t *TypeStatuses) Drift() bool {
+	for _, s := range *t {
+		if s.Status != STATUS_SYNC {
+			return true
+		}
+	}
+	return false
+}
+
+// Output statuses
+//   - [format] = "table" or "json"
+//   - [verbose] = true: include in-sync
+func (t *TypeStatuses) Output(format string, verbose bool) (err error) {
+	var statuses TypeStatuses
+	for _, s := range *t {
+		if verbose || ezlog.GetLogLevel() >= ezlog.DEBUG || s.Status != STATUS_SYNC {
+			statuses = append(statuses, s)
+		}
+	}
+	switch format {
+	case OUTPUT_JSON:
+		var data []byte
+		if statuses == nil {
+			statuses = TypeStatuses{} // "[]" instead of "null"
+		}
+		if data, err = json.MarshalIndent(statuses, "", "  "); err == nil {
+			fmt.Println(string(data))
+		}
+	case OUTPUT_TABLE:
+		tab_Writer := tabwriter.NewWriter(os.Stdout, 1, 1, 1, ' ', 0)
+		for _, s := range statuses {
+			fmt.Fprintln(tab_Writer, strings.Join([]string{
+				s.Status.String(),
+				s.SrcMode.String(),
+				s.SrcPath,
+				"->",
+				s.DesPath,
+			}, "\t"))
+		}
+		tab_Writer.Flush()
+	default:
+		err = errors.New("invalid output format: " + format)
+	}
+	return err
+}
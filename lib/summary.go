@@ -0,0 +1,79 @@
+/*
+Copyright © 2025 John, Sing Dao, Siu <john.sd.siu@gmail.com>
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+
+package lib
+
+import (
+	"fmt"
+	"time"
+)
+
+// Counts of an update run
+type TypeSummary struct {
+	Append   int           `json:"Append"`
+	Bytes    int64         `json:"Bytes"` // COPY/APPEND: bytes written, or to be written in dry run
+	Chmod    int           `json:"Chmod"`
+	Copy     int           `json:"Copy"`
+	Duration time.Duration `json:"Duration"`
+	Errors   int           `json:"Errors"`
+	Link     int           `json:"Link"`
+	Save     bool          `json:"Save"`
+	Scripts  int           `json:"Scripts"` // scripts run, or to be run in dry run
+	Skip     int           `json:"Skip"`
+}
+
+// Summary of records, Errors, Duration and Scripts are set by caller
+func (t *TypeDotfileRecords) Summary(save bool) *TypeSummary {
+	summary := TypeSummary{Save: save}
+	for _, r := range *t {
+		switch r.FileProcMode {
+		case APPEND:
+			summary.Append++
+		case CHMOD:
+			summary.Chmod++
+		case COPY:
+			summary.Copy++
+		case LINK:
+			summary.Link++
+		case SKIP:
+			summary.Skip++
+		}
+		if r.FileProcMode == APPEND || r.FileProcMode == COPY {
+			summary.Bytes += r.DataSize
+		}
+	}
+	return &summary
+}
+
+// true: any file changed, or to be changed in dry run
+func (t *TypeSummary) Changed() bool {
+	return t.Append+t.Chmod+t.Copy+t.Link+t.Scripts > 0
+}
+
+func (t *TypeSummary) String() string {
+	var str string
+	if !t.Save {
+		str = "DryRun: "
+	}
+	return str + fmt.Sprintf("%d copied, %d appended, %d chmodded, %d linked, %d scripts run, %d skipped, %d errors, %d bytes written, %s",
+		t.Copy, t.Append, t.Chmod, t.Link, t.Scripts, t.Skip, t.Errors, t.Bytes, t.Duration.Round(time.Millisecond))
+}
@@ -0,0 +1,95 @@
+/*
+Copyright © 2025 John, Sing Dao, Siu <john.sd.siu@gmail.com>
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+
+package lib
+
+import (
+	"runtime"
+	"sync"
+)
+
+// A file to process
+type dotfileTask struct {
+	df      *TypeDotfile
+	desPath string
+	srcPath string
+	// --- result
+	err    error
+	record *TypeDotfileRecord
+}
+
+func (t *dotfileTask) run() {
+	if t.df.Mode == LINK {
+		t.record, t.err = t.df.processLink(t.srcPath, t.desPath)
+	} else {
+		t.record, t.err = t.df.processFile(t.srcPath, t.desPath)
+	}
+}
+
+// Run [tasks] with [jobs] workers, [jobs] <= 0: number of CPU.
+//
+// Tasks of same destination run one by one in order, so APPEND from
+// multiple sources keeps its order. Records are returned in order of
+// [tasks], errors are in order of [tasks], each an [ErrFileFailed] of its destination.
+func runTasks(tasks []*dotfileTask, jobs int) (records TypeDotfileRecords, errList []error) {
+	var (
+		ch     = make(chan []*dotfileTask)
+		groups [][]*dotfileTask
+		index  = make(map[string]int) // desPath -> index in groups
+		wg     sync.WaitGroup
+	)
+	for _, task := range tasks {
+		i, ok := index[task.desPath]
+		if !ok {
+			i = len(groups)
+			index[task.desPath] = i
+			groups = append(groups, nil)
+		}
+		groups[i] = append(groups[i], task)
+	}
+	if jobs <= 0 {
+		jobs = runtime.NumCPU()
+	}
+	for range min(jobs, len(groups)) {
+		wg.Go(func() {
+			for group := range ch {
+				for _, task := range group {
+					task.run()
+				}
+			}
+		})
+	}
+	for _, group := range groups {
+		ch <- group
+	}
+	close(ch)
+	wg.Wait()
+
+	for _, task := range tasks {
+		if task.err == nil {
+			records = append(records, task.record)
+		} else {
+			errList = append(errList, &TypeError{Err: task.err, Path: task.desPath, Sentinel: ErrFileFailed})
+		}
+	}
+	return records, errList
+}
@@ -0,0 +1,90 @@
+/*
+Copyright © 2025 John, Sing Dao, Siu <john.sd.siu@gmail.com>
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+
+package lib
+
+import (
+	"bytes"
+	"os"
+	"os/user"
+	"path"
+	"runtime"
+	"strings"
+	"text/template"
+)
+
+const TMPL_EXT = ".tmpl"
+
+// Data available to templates, eg. {{ .Hostname }}, {{ .Env.EDITOR }}, {{ .Data.email }}
+type TypeTemplateData struct {
+	Arch     string            `json:"Arch"`
+	Data     map[string]any    `json:"Data"` // user variables from config "Data" section
+	Env      map[string]string `json:"Env"`
+	Home     string            `json:"Home"`
+	Hostname string            `json:"Hostname"`
+	OS       string            `json:"OS"`
+	Username string            `json:"Username"`
+}
+
+func (t *TypeTemplateData) New(data map[string]any) *TypeTemplateData {
+	t.Arch = runtime.GOARCH
+	t.Data = data
+	t.Env = make(map[string]string)
+	for _, kv := range os.Environ() {
+		if k, v, ok := strings.Cut(kv, "="); ok {
+			t.Env[k] = v
+		}
+	}
+	t.Home, _ = os.UserHomeDir()
+	t.Hostname, _ = os.Hostname()
+	t.OS = runtime.GOOS
+	if u, e := user.Current(); e == nil {
+		t.Username = u.Username
+	}
+	return t
+}
+
+// Check if [p] is a template, eg. gitconfig.tmpl, gitconfig.tmpl##os.linux, gitconfig.tmpl.age
+func isTemplate(p string) bool {
+	return strings.HasSuffix(trimEncrypted(trimAlt(p)), TMPL_EXT)
+}
+
+// Strip template extension from [p]
+func trimTemplate(p string) string {
+	return strings.TrimSuffix(p, TMPL_EXT)
+}
+
+// Render template [text] of [srcPath] with [data].
+// Missing key is an error, so typo does not end up in destination.
+func renderTemplate(srcPath string, text []byte, data *TypeTemplateData) (out []byte, err error) {
+	var (
+		buf  bytes.Buffer
+		tmpl *template.Template
+	)
+	if data == nil {
+		data = new(TypeTemplateData).New(nil)
+	}
+	if tmpl, err = template.New(path.Base(srcPath)).Option("missingkey=error").Parse(string(text)); err == nil {
+		err = tmpl.Execute(&buf, data)
+	}
+	return buf.Bytes(), err
+}
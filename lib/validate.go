@@ -0,0 +1,300 @@
+/*
+Copyright © 2025 John, Sing Dao, Siu <john.sd.siu@gmail.com>
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+
+package lib
+
+import (
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+
+	"filippo.io/age"
+	"github.com/J-Siu/go-helper/v2/file"
+	"github.com/bmatcuk/doublestar/v4"
+)
+
+// Problem found in configuration
+type TypeConfProblem struct {
+	File string `json:"File"` // file of the value, empty if not from a file
+	Key  string `json:"Key"`  // key path, eg. DirCP[1], Profiles.work.dircp[0]
+	Msg  string `json:"Msg"`
+}
+
+func (t *TypeConfProblem) Error() string {
+	if t.File == "" {
+		return t.Key + ": " + t.Msg
+	}
+	return t.File + ": " + t.Key + ": " + t.Msg
+}
+
+// List settings, in order of checking
+var confListKeys = []string{"DirAP", "DirCP", "DirLN", "DirSkip", "FileSkip"}
+
+// Value of a list setting, with its key path and file
+type confItem struct {
+	file  string
+	key   string
+	value string
+}
+
+// Check configuration and return all problems found. Should be called after reading
+// config file, before applyProfile() and expand(), so problems point to the file.
+//   - unknown keys
+//   - empty or invalid DirSkip/FileSkip pattern, invalid SkipMatch
+//   - invalid Compare, DirScripts, Symlink, Recipients
+//   - hook without command, Path hook without valid Match pattern, negative timeout
+//   - DirAP/DirCP/DirLN directory not found
+//   - source directories overlapping each other
+//   - DirDest inside a source directory
+//
+// Only selected profiles are checked.
+func (t *TypeConf) Validate() (problems []*TypeConfProblem) {
+	problem := func(item confItem, msg string) {
+		problems = append(problems, &TypeConfProblem{File: item.file, Key: item.key, Msg: msg})
+	}
+
+	problems = t.validateKeys()
+
+	// Settings after profiles
+	items := make(map[string][]confItem)
+	for _, name := range confListKeys {
+		for i, v := range *confList(t, name) {
+			key := name + "[" + strconv.Itoa(i) + "]"
+			items[name] = append(items[name], confItem{t.Origin[key], key, v})
+		}
+	}
+	des := confItem{t.Origin["DirDest"], "DirDest", t.DirDest}
+	names, err := t.profileNames()
+	if err != nil {
+		problem(confItem{key: "Profile"}, err.Error())
+	}
+	for _, pName := range names {
+		p := t.Profiles[pName]
+		for _, name := range confListKeys {
+			if p.Override {
+				items[name] = nil
+			}
+			for i, v := range *profileList(p, name) {
+				key := "Profiles." + pName + "." + strings.ToLower(name) + "[" + strconv.Itoa(i) + "]"
+				items[name] = append(items[name], confItem{t.Origin[key], key, v})
+			}
+		}
+		if p.DirDest != "" {
+			key := "Profiles." + pName + ".dirdest"
+			des = confItem{t.Origin[key], key, p.DirDest}
+		}
+	}
+
+	for i, r := range t.Recipients {
+		if _, e := age.ParseX25519Recipient(r); e != nil {
+			key := "Recipients[" + strconv.Itoa(i) + "]"
+			problem(confItem{t.Origin[key], key, r}, "invalid age recipient")
+		}
+	}
+	switch t.Symlink {
+	case "", SYMLINK_FOLLOW, SYMLINK_REPLACE:
+	default:
+		problem(confItem{t.Origin["Symlink"], "Symlink", t.Symlink}, "not "+SYMLINK_FOLLOW+" or "+SYMLINK_REPLACE)
+	}
+	if d := t.DirScripts; d == "" || d == "." || d == ".." || strings.Contains(d, "/") {
+		problem(confItem{t.Origin["DirScripts"], "DirScripts", d}, "not a directory name")
+	}
+	switch t.Compare {
+	case "", COMPARE_BYTES, COMPARE_HASH, COMPARE_MTIME_SIZE:
+	default:
+		problem(confItem{t.Origin["Compare"], "Compare", t.Compare}, "not "+COMPARE_MTIME_SIZE+", "+COMPARE_HASH+" or "+COMPARE_BYTES)
+	}
+
+	// Hooks, keys are lowercase after reading
+	if t.Hooks.Timeout < 0 {
+		problem(confItem{t.Origin["Hooks.timeout"], "Hooks.timeout", ""}, "negative timeout")
+	}
+	for _, name := range []string{HOOK_PATH, HOOK_POST_UPDATE, HOOK_PRE_UPDATE} {
+		for i, hook := range t.Hooks.Hooks(name) {
+			key := "Hooks." + strings.ToLower(name) + "[" + strconv.Itoa(i) + "]"
+			item := confItem{t.Origin[key], key, hook.Command}
+			if strings.TrimSpace(hook.Command) == "" {
+				problem(item, "empty hook command")
+			}
+			if hook.Timeout < 0 {
+				problem(item, "negative timeout")
+			}
+			if name == HOOK_PATH && len(hook.Match) == 0 {
+				problem(item, "no Match pattern")
+			}
+			for _, pattern := range hook.Match {
+				if !doublestar.ValidatePattern(pattern) {
+					problem(item, "invalid glob pattern: "+pattern)
+				}
+			}
+		}
+	}
+
+	// Skip patterns
+	if t.SkipMatch != "" && t.SkipMatch != SKIP_MATCH_GLOB && t.SkipMatch != SKIP_MATCH_SUBSTRING {
+		problem(confItem{t.Origin["SkipMatch"], "SkipMatch", t.SkipMatch}, "not "+SKIP_MATCH_GLOB+" or "+SKIP_MATCH_SUBSTRING)
+	}
+	for _, name := range []string{"DirSkip", "FileSkip"} {
+		for _, item := range items[name] {
+			if strings.TrimSpace(item.value) == "" {
+				problem(item, "empty skip pattern")
+			} else if t.SkipMatch != SKIP_MATCH_SUBSTRING {
+				for _, rule := range parseSkip([]string{item.value}, false, false) {
+					if !doublestar.ValidatePattern(rule.pattern) {
+						problem(item, "invalid glob pattern")
+					}
+				}
+			}
+		}
+	}
+
+	// Source directories
+	var srcs []confItem
+	for _, name := range []string{"DirAP", "DirCP", "DirLN"} {
+		for _, item := range items[name] {
+			item.value = filepath.Clean(file.TildeEnvExpand(item.value))
+			if !file.IsDir(item.value) {
+				problem(item, "directory not found: "+item.value)
+			}
+			for _, src := range srcs {
+				if pathWithin(src.value, item.value) || pathWithin(item.value, src.value) {
+					problem(item, "overlaps "+src.key+": "+src.value)
+				}
+			}
+			srcs = append(srcs, item)
+		}
+	}
+	des.value = filepath.Clean(file.TildeEnvExpand(des.value))
+	for _, src := range srcs {
+		if pathWithin(src.value, des.value) {
+			problem(des, "inside source "+src.key+": "+src.value)
+		}
+	}
+
+	return problems
+}
+
+// Unknown keys of all files read, as written in the file. Field names are case-sensitive.
+func (t *TypeConf) validateKeys() (problems []*TypeConfProblem) {
+	for _, f := range t.files {
+		for _, key := range unknownKeys(reflect.TypeOf(TypeConf{}), f.raw, "") {
+			problems = append(problems, &TypeConfProblem{File: f.path, Key: key.name, Msg: key.msg})
+		}
+	}
+	return problems
+}
+
+// Unknown key and its message
+type unknownKey struct {
+	name string
+	msg  string
+}
+
+// Unknown keys of [value] decoded for type [vType], recursively, sorted
+//   - [keyPath] = key path of [value], "" for top level
+func unknownKeys(vType reflect.Type, value any, keyPath string) (keys []*unknownKey) {
+	join := func(k string) string {
+		if keyPath == "" {
+			return k
+		}
+		return keyPath + "." + k
+	}
+	if vType.Kind() == reflect.Pointer {
+		vType = vType.Elem()
+	}
+	switch vType.Kind() {
+	case reflect.Struct:
+		m, _ := value.(map[string]any)
+		for _, k := range sortedKeys(m) {
+			if f, ok := confField(vType, k, false); ok {
+				keys = append(keys, unknownKeys(f.Type, m[k], join(k))...)
+			} else if f, ok := confField(vType, k, true); ok {
+				keys = append(keys, &unknownKey{join(k), "unknown key, field names are case-sensitive: " + f.Name})
+			} else {
+				keys = append(keys, &unknownKey{join(k), "unknown key"})
+			}
+		}
+	case reflect.Map:
+		m, _ := value.(map[string]any)
+		for _, k := range sortedKeys(m) {
+			keys = append(keys, unknownKeys(vType.Elem(), m[k], join(k))...)
+		}
+	case reflect.Slice:
+		list, _ := value.([]any)
+		for i, v := range list {
+			keys = append(keys, unknownKeys(vType.Elem(), v, keyPath+"["+strconv.Itoa(i)+"]")...)
+		}
+	}
+	return keys
+}
+
+// Exported, not embedded, field [name] of struct [sType], case-insensitive if [fold]
+func confField(sType reflect.Type, name string, fold bool) (f reflect.StructField, ok bool) {
+	for i := 0; i < sType.NumField(); i++ {
+		f = sType.Field(i)
+		if f.Anonymous || !f.IsExported() || f.Tag.Get("json") == "-" {
+			continue
+		}
+		if f.Name == name || fold && strings.EqualFold(f.Name, name) {
+			return f, true
+		}
+	}
+	return f, false
+}
+
+// Keys of [m], sorted
+func sortedKeys(m map[string]any) (keys []string) {
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// Check if [p] is [dir] or inside it
+func pathWithin(dir, p string) bool {
+	return p == dir || strings.HasPrefix(p, strings.TrimSuffix(dir, "/")+"/")
+}
+
+// List setting [name] of configuration
+func confList(t *TypeConf, name string) *[]string {
+	return map[string]*[]string{
+		"DirAP":    &t.DirAP,
+		"DirCP":    &t.DirCP,
+		"DirLN":    &t.DirLN,
+		"DirSkip":  &t.DirSkip,
+		"FileSkip": &t.FileSkip,
+	}[name]
+}
+
+// List setting [name] of profile
+func profileList(p *TypeProfile, name string) *[]string {
+	return map[string]*[]string{
+		"DirAP":    &p.DirAP,
+		"DirCP":    &p.DirCP,
+		"DirLN":    &p.DirLN,
+		"DirSkip":  &p.DirSkip,
+		"FileSkip": &p.FileSkip,
+	}[name]
+}